@@ -2,19 +2,107 @@ package git
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/cli/safeexec"
 )
 
+// Error represents a git command that failed to run or exited with a non-zero status.
+type Error struct {
+	// Args are the arguments git was run with.
+	Args []string
+
+	// ExitCode is the exit code of git, or -1 if git could not be run.
+	ExitCode int
+
+	// Stderr is the standard error output of git.
+	Stderr string
+
+	err error
+}
+
+// Allow Error to satisfy error interface.
+func (e *Error) Error() string {
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		return fmt.Sprintf("failed to run git: %s. error: %v", stderr, e.err)
+	}
+	return fmt.Sprintf("failed to run git: %v", e.err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
 func Exec(args ...string) (stdOut, stdErr bytes.Buffer, err error) {
-	path, err := path()
+	return ExecInDir("", args...)
+}
+
+// ExecInDir runs git in the specified directory as if started with "git -C dir".
+// An empty dir runs git in the current directory.
+func ExecInDir(dir string, args ...string) (stdOut, stdErr bytes.Buffer, err error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd, err := Command(context.Background(), "", "", nil, args...)
 	if err != nil {
-		err = fmt.Errorf("could not find git executable in PATH. error: %w", err)
 		return
 	}
-	return run(path, nil, args...)
+	return Run(cmd, nil)
+}
+
+// Command returns an *exec.Cmd that runs the git executable at gitPath, or the one
+// found in PATH if gitPath is empty, with the specified arguments in dir. Variables
+// of the form "KEY=value" in env are appended to the environment of the process.
+// If git can not be found the error is an *Error.
+func Command(ctx context.Context, gitPath, dir string, env []string, args ...string) (*exec.Cmd, error) {
+	if gitPath == "" {
+		var err error
+		gitPath, err = path()
+		if err != nil {
+			return nil, &Error{
+				Args:     args,
+				ExitCode: -1,
+				err:      fmt.Errorf("could not find git executable in PATH. error: %w", err),
+			}
+		}
+	}
+	cmd := exec.CommandContext(ctx, gitPath, args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd, nil
+}
+
+// Run runs cmd and returns its standard output and standard error output. The standard
+// error output is also copied to stderr if it is not nil. If the command can not be run
+// or exits with a non-zero status the error is an *Error.
+func Run(cmd *exec.Cmd, stderr io.Writer) (stdOut, stdErr bytes.Buffer, err error) {
+	cmd.Stdout = &stdOut
+	cmd.Stderr = &stdErr
+	if stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stdErr, stderr)
+	}
+	if runErr := cmd.Run(); runErr != nil {
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		err = &Error{
+			Args:     cmd.Args[1:],
+			ExitCode: exitCode,
+			Stderr:   stdErr.String(),
+			err:      runErr,
+		}
+	}
+	return
 }
 
 func path() (string, error) {
@@ -23,15 +111,8 @@ func path() (string, error) {
 
 func run(path string, env []string, args ...string) (stdOut, stdErr bytes.Buffer, err error) {
 	cmd := exec.Command(path, args...)
-	cmd.Stdout = &stdOut
-	cmd.Stderr = &stdErr
 	if env != nil {
 		cmd.Env = env
 	}
-	err = cmd.Run()
-	if err != nil {
-		err = fmt.Errorf("failed to run git: %s. error: %w", stdErr.String(), err)
-		return
-	}
-	return
+	return Run(cmd, nil)
 }
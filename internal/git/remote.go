@@ -41,12 +41,17 @@ func remoteNameSortScore(name string) int {
 }
 
 func Remotes() (RemoteSet, error) {
-	list, err := listRemotes()
+	return RemotesInDir("")
+}
+
+// RemotesInDir returns the remotes of the repository in the specified directory.
+func RemotesInDir(dir string) (RemoteSet, error) {
+	list, err := listRemotes(dir)
 	if err != nil {
 		return nil, err
 	}
 	remotes := parseRemotes(list)
-	setResolvedRemotes(remotes)
+	setResolvedRemotes(dir, remotes)
 	sort.Sort(remotes)
 	return remotes, nil
 }
@@ -65,8 +70,8 @@ func (rs RemoteSet) FilterByHosts(hosts []string) RemoteSet {
 	return filtered
 }
 
-func listRemotes() ([]string, error) {
-	stdOut, _, err := Exec("remote", "-v")
+func listRemotes(dir string) ([]string, error) {
+	stdOut, _, err := ExecInDir(dir, "remote", "-v")
 	if err != nil {
 		return nil, err
 	}
@@ -124,8 +129,8 @@ func parseRemotes(gitRemotes []string) RemoteSet {
 	return remotes
 }
 
-func setResolvedRemotes(remotes RemoteSet) {
-	stdOut, _, err := Exec("config", "--get-regexp", `^remote\..*\.gh-resolved$`)
+func setResolvedRemotes(dir string, remotes RemoteSet) {
+	stdOut, _, err := ExecInDir(dir, "config", "--get-regexp", `^remote\..*\.gh-resolved$`)
 	if err != nil {
 		return
 	}
@@ -98,3 +98,22 @@ func TestParseRemotes(t *testing.T) {
 	assert.Equal(t, "koke", r[4].Owner)
 	assert.Equal(t, "grit", r[4].Repo)
 }
+
+func TestRemotesInDir(t *testing.T) {
+	tempDir := t.TempDir()
+	_, _, err := ExecInDir(tempDir, "init", "--quiet")
+	assert.NoError(t, err)
+	_, _, err = ExecInDir(tempDir, "remote", "add", "origin", "https://github.com/monalisa/origin.git")
+	assert.NoError(t, err)
+	_, _, err = ExecInDir(tempDir, "config", "remote.origin.gh-resolved", "base")
+	assert.NoError(t, err)
+	subDir := filepath.Join(tempDir, "sub")
+	assert.NoError(t, os.Mkdir(subDir, 0755))
+
+	rs, err := RemotesInDir(subDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rs))
+	assert.Equal(t, "origin", rs[0].Name)
+	assert.Equal(t, "monalisa", rs[0].Owner)
+	assert.Equal(t, "base", rs[0].Resolved)
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"errors"
+	"strings"
+)
+
+// URLRewrites are the url.<base>.insteadOf rules of the git configuration, as described
+// in git-config(1). git applies them to the URLs it reports for remotes, but URLs given
+// by users, such as "gh:OWNER/REPO", have to be rewritten before they are parsed.
+type URLRewrites struct {
+	insteadOf []urlRewrite
+}
+
+type urlRewrite struct {
+	base   string
+	prefix string
+}
+
+// ReadURLRewrites reads the URL rewrite rules of the repository in the specified directory.
+// An empty dir reads the rules of the current directory.
+func ReadURLRewrites(dir string) (URLRewrites, error) {
+	entries, err := readConfigRegexp(dir, `^url\..+\.insteadof$`)
+	if err != nil {
+		return URLRewrites{}, err
+	}
+	return parseURLRewrites(entries), nil
+}
+
+// Rewrite applies the insteadOf rule with the longest matching prefix to rawURL.
+func (r URLRewrites) Rewrite(rawURL string) string {
+	if rewritten, ok := applyRewrites(r.insteadOf, rawURL); ok {
+		return rewritten
+	}
+	return rawURL
+}
+
+func applyRewrites(rewrites []urlRewrite, rawURL string) (string, bool) {
+	var longest *urlRewrite
+	for i, rw := range rewrites {
+		if strings.HasPrefix(rawURL, rw.prefix) && (longest == nil || len(rw.prefix) > len(longest.prefix)) {
+			longest = &rewrites[i]
+		}
+	}
+	if longest == nil {
+		return "", false
+	}
+	return longest.base + strings.TrimPrefix(rawURL, longest.prefix), true
+}
+
+// parseURLRewrites returns the rewrite rules of configuration entries, ignoring other entries.
+func parseURLRewrites(entries []configEntry) URLRewrites {
+	var r URLRewrites
+	for _, e := range entries {
+		if !strings.HasPrefix(e.key, "url.") {
+			continue
+		}
+		// Section and variable names are case-insensitive, but the base URL is not.
+		lowerKey := strings.ToLower(e.key)
+		if strings.HasSuffix(lowerKey, ".insteadof") {
+			base := e.key[len("url.") : len(e.key)-len(".insteadof")]
+			r.insteadOf = append(r.insteadOf, urlRewrite{base: base, prefix: e.value})
+		}
+	}
+	return r
+}
+
+type configEntry struct {
+	key   string
+	value string
+}
+
+// readConfigRegexp returns the configuration entries with keys matching regexp. No entries
+// and no error are returned if none match. Keys can contain spaces and values newlines, so
+// the entries are read in the NUL-terminated format.
+func readConfigRegexp(dir, regexp string) ([]configEntry, error) {
+	stdOut, _, err := ExecInDir(dir, "config", "-z", "--get-regexp", regexp)
+	if err != nil {
+		var gitErr *Error
+		if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+			// git config exits with status 1 if no key matches.
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []configEntry
+	for _, entry := range strings.Split(stdOut.String(), "\x00") {
+		if entry == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(entry, "\n")
+		entries = append(entries, configEntry{key: key, value: value})
+	}
+	return entries, nil
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLRewrites(t *testing.T) {
+	rewrites := parseURLRewrites([]configEntry{
+		{key: "url.https://github.com/.insteadof", value: "gh:"},
+		{key: "url.https://github.com/.insteadof", value: "github:"},
+		{key: "url.https://mirror.example.com/github/.insteadOf", value: "gh:github/"},
+		{key: "url.https://GHE.example.com/.insteadof", value: "ghe:"},
+		{key: "remote.origin.url", value: "gh:monalisa/octo-cat"},
+	})
+
+	tests := []struct {
+		name   string
+		rawURL string
+		want   string
+	}{
+		{
+			name:   "shortcut",
+			rawURL: "gh:monalisa/octo-cat",
+			want:   "https://github.com/monalisa/octo-cat",
+		},
+		{
+			name:   "multiple prefixes for the same base",
+			rawURL: "github:monalisa/octo-cat",
+			want:   "https://github.com/monalisa/octo-cat",
+		},
+		{
+			name:   "longest prefix wins",
+			rawURL: "gh:github/cli",
+			want:   "https://mirror.example.com/github/cli",
+		},
+		{
+			name:   "case of base URL is preserved",
+			rawURL: "ghe:monalisa/octo-cat",
+			want:   "https://GHE.example.com/monalisa/octo-cat",
+		},
+		{
+			name:   "no matching rule",
+			rawURL: "git@example.com:monalisa/octo-cat.git",
+			want:   "git@example.com:monalisa/octo-cat.git",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rewrites.Rewrite(tt.rawURL))
+		})
+	}
+}
+
+func TestReadURLRewrites(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	tempDir := t.TempDir()
+	_, _, err := ExecInDir(tempDir, "init", "--quiet")
+	assert.NoError(t, err)
+
+	rewrites, err := ReadURLRewrites(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "gh:monalisa/octo-cat", rewrites.Rewrite("gh:monalisa/octo-cat"))
+
+	_, _, err = ExecInDir(tempDir, "config", "url.https://github.com/.insteadOf", "gh:")
+	assert.NoError(t, err)
+	rewrites, err = ReadURLRewrites(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/monalisa/octo-cat", rewrites.Rewrite("gh:monalisa/octo-cat"))
+}
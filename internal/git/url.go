@@ -2,8 +2,11 @@ package git
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
 )
 
 func IsURL(u string) bool {
@@ -64,20 +67,162 @@ func ParseURL(rawURL string) (u *url.URL, err error) {
 	return
 }
 
+// RepoURLErrorReason describes why a URL is not a repository URL.
+type RepoURLErrorReason int
+
+const (
+	// RepoURLNoHostname is the reason for URLs without a hostname, such as file paths.
+	RepoURLNoHostname RepoURLErrorReason = iota
+	// RepoURLBasePathMismatch is the reason for URLs of hosts with a base path
+	// whose path does not start with it.
+	RepoURLBasePathMismatch
+	// RepoURLMissingOwner is the reason for URLs without an owner segment.
+	RepoURLMissingOwner
+	// RepoURLMissingName is the reason for URLs without a repository name segment.
+	RepoURLMissingName
+	// RepoURLTrailingPath is the reason for URLs with segments after the repository name.
+	RepoURLTrailingPath
+	// RepoURLInvalidSegment is the reason for URLs with an owner or name segment that
+	// is not valid percent-encoding or that contains an encoded slash.
+	RepoURLInvalidSegment
+)
+
+func (r RepoURLErrorReason) String() string {
+	switch r {
+	case RepoURLNoHostname:
+		return "no hostname"
+	case RepoURLBasePathMismatch:
+		return "path outside of base path"
+	case RepoURLMissingOwner:
+		return "missing owner"
+	case RepoURLMissingName:
+		return "missing repository name"
+	case RepoURLTrailingPath:
+		return "path after repository name"
+	case RepoURLInvalidSegment:
+		return "invalid path segment"
+	}
+	return "unknown"
+}
+
+// RepoURLError is returned by RepoInfoFromURL for URLs that are not repository URLs.
+type RepoURLError struct {
+	URL    *url.URL
+	Reason RepoURLErrorReason
+}
+
+func (e *RepoURLError) Error() string {
+	if e.Reason == RepoURLNoHostname {
+		return "no hostname detected"
+	}
+	return fmt.Sprintf("invalid path: %s: %s", e.URL.Path, e.Reason)
+}
+
+// RepoURLOptions holds optional configuration for RepoInfoFromURLWithOptions.
+type RepoURLOptions struct {
+	// BasePaths maps hostnames to the path that GitHub Enterprise Server is served
+	// under on that host, such as "/github" for https://example.com/github/OWNER/REPO.
+	BasePaths map[string]string
+
+	// KeepPort includes ports other than the default port of the scheme in the
+	// returned host, such as "example.com:8443". Default is to strip ports.
+	KeepPort bool
+
+	// AllowTrailingPath accepts path segments after the repository name, such as
+	// those of web URLs of files or pull requests. Default is to reject them.
+	AllowTrailingPath bool
+}
+
+// defaultPorts are the ports that are not included in hosts with RepoURLOptions.KeepPort.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ssh":   "22",
+	"git":   "9418",
+}
+
 // Extract GitHub repository information from a git remote URL.
+// If the URL is not a repository URL the error is a *RepoURLError.
 func RepoInfoFromURL(u *url.URL) (host string, owner string, name string, err error) {
+	return RepoInfoFromURLWithOptions(u, RepoURLOptions{})
+}
+
+// RepoInfoFromURLWithOptions extracts GitHub repository information from a git remote or web URL
+// with the specified options. Owner and name are percent-decoded and the ".git" suffix of the name
+// is removed. If the URL is not a repository URL the error is a *RepoURLError.
+func RepoInfoFromURLWithOptions(u *url.URL, opts RepoURLOptions) (host string, owner string, name string, err error) {
 	if u.Hostname() == "" {
-		return "", "", "", fmt.Errorf("no hostname detected")
+		return "", "", "", &RepoURLError{URL: u, Reason: RepoURLNoHostname}
+	}
+	host = normalizeHostname(u.Hostname())
+
+	segments := splitPath(u.EscapedPath())
+	if basePath := splitPath(BasePath(u, opts.BasePaths)); len(basePath) > 0 {
+		if len(segments) < len(basePath) {
+			return "", "", "", &RepoURLError{URL: u, Reason: RepoURLBasePathMismatch}
+		}
+		for i := range basePath {
+			if !strings.EqualFold(segments[i], basePath[i]) {
+				return "", "", "", &RepoURLError{URL: u, Reason: RepoURLBasePathMismatch}
+			}
+		}
+		segments = segments[len(basePath):]
+	}
+
+	switch {
+	case len(segments) == 0 || segments[0] == "":
+		return "", "", "", &RepoURLError{URL: u, Reason: RepoURLMissingOwner}
+	case len(segments) == 1 || segments[1] == "":
+		return "", "", "", &RepoURLError{URL: u, Reason: RepoURLMissingName}
+	case len(segments) > 2 && !opts.AllowTrailingPath:
+		return "", "", "", &RepoURLError{URL: u, Reason: RepoURLTrailingPath}
+	}
+
+	owner, err = url.PathUnescape(segments[0])
+	if err != nil || strings.Contains(owner, "/") {
+		return "", "", "", &RepoURLError{URL: u, Reason: RepoURLInvalidSegment}
+	}
+	name, err = url.PathUnescape(segments[1])
+	if err != nil || strings.Contains(name, "/") {
+		return "", "", "", &RepoURLError{URL: u, Reason: RepoURLInvalidSegment}
+	}
+	name = strings.TrimSuffix(name, ".git")
+	if name == "" {
+		return "", "", "", &RepoURLError{URL: u, Reason: RepoURLMissingName}
+	}
+
+	if port := u.Port(); opts.KeepPort && port != "" && port != defaultPorts[u.Scheme] {
+		host = net.JoinHostPort(host, port)
 	}
 
-	parts := strings.SplitN(strings.Trim(u.Path, "/"), "/", 3)
-	if len(parts) != 2 {
-		return "", "", "", fmt.Errorf("invalid path: %s", u.Path)
+	return host, owner, name, nil
+}
+
+// BasePath returns the base path that basePaths configures for the host of u, or
+// an empty string if there is none. Hostnames are compared after normalization.
+func BasePath(u *url.URL, basePaths map[string]string) string {
+	host := normalizeHostname(u.Hostname())
+	for h, p := range basePaths {
+		if normalizeHostname(h) == host {
+			return p
+		}
 	}
+	return ""
+}
 
-	return normalizeHostname(u.Hostname()), parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+// splitPath returns the segments of a path without leading and trailing slashes.
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
 }
 
+// normalizeHostname normalizes the hostname of a remote URL with auth.NewHost. The
+// "www." prefix is only stripped here, as it is an alias of the host in web URLs,
+// whereas auth.NewHost has to keep it for GitHub Enterprise Server hosts named so.
 func normalizeHostname(h string) string {
-	return strings.ToLower(strings.TrimPrefix(h, "www."))
+	h = strings.ToLower(h)
+	return auth.NewHost(strings.TrimPrefix(h, "www.")).Name()
 }
@@ -253,11 +253,18 @@ func TestRepoInfoFromURL(t *testing.T) {
 			wantOwner: "monalisa",
 			wantRepo:  "octo-cat",
 		},
+		{
+			name:      "github.com subdomain",
+			input:     "https://ssh.github.com/monalisa/octo-cat.git",
+			wantHost:  "github.com",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
 		{
 			name:       "too many path components",
 			input:      "https://github.com/monalisa/octo-cat/pulls",
 			wantErr:    true,
-			wantErrMsg: "invalid path: /monalisa/octo-cat/pulls",
+			wantErrMsg: "invalid path: /monalisa/octo-cat/pulls: path after repository name",
 		},
 		{
 			name:      "non-GitHub hostname",
@@ -317,3 +324,156 @@ func TestRepoInfoFromURL(t *testing.T) {
 		})
 	}
 }
+
+func TestRepoInfoFromURLWithOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		opts       RepoURLOptions
+		wantHost   string
+		wantOwner  string
+		wantRepo   string
+		wantReason RepoURLErrorReason
+		wantErr    bool
+	}{
+		{
+			name:      "GHES under base path",
+			input:     "https://example.com/github/monalisa/octo-cat.git",
+			opts:      RepoURLOptions{BasePaths: map[string]string{"example.com": "/github/"}},
+			wantHost:  "example.com",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
+		{
+			name:      "base path matching is case-insensitive",
+			input:     "https://Example.com/github/monalisa/octo-cat",
+			opts:      RepoURLOptions{BasePaths: map[string]string{"www.Example.com": "GitHub"}},
+			wantHost:  "example.com",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
+		{
+			name:       "outside of base path",
+			input:      "https://example.com/monalisa/octo-cat",
+			opts:       RepoURLOptions{BasePaths: map[string]string{"example.com": "/github/enterprise"}},
+			wantReason: RepoURLBasePathMismatch,
+			wantErr:    true,
+		},
+		{
+			name:      "base path of other host is ignored",
+			input:     "https://github.com/monalisa/octo-cat",
+			opts:      RepoURLOptions{BasePaths: map[string]string{"example.com": "/github"}},
+			wantHost:  "github.com",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
+		{
+			name:      "non-standard port",
+			input:     "https://example.com:8443/monalisa/octo-cat.git",
+			opts:      RepoURLOptions{KeepPort: true},
+			wantHost:  "example.com:8443",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
+		{
+			name:      "default port",
+			input:     "https://example.com:443/monalisa/octo-cat.git",
+			opts:      RepoURLOptions{KeepPort: true},
+			wantHost:  "example.com",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
+		{
+			name:      "port is stripped by default",
+			input:     "https://example.com:8443/monalisa/octo-cat.git",
+			wantHost:  "example.com",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
+		{
+			name:      "trailing path",
+			input:     "https://github.com/monalisa/octo-cat/pull/123/files",
+			opts:      RepoURLOptions{AllowTrailingPath: true},
+			wantHost:  "github.com",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
+		{
+			name:       "trailing path is rejected by default",
+			input:      "https://github.com/monalisa/octo-cat/pull/123",
+			wantReason: RepoURLTrailingPath,
+			wantErr:    true,
+		},
+		{
+			name:      "git suffix with trailing slash",
+			input:     "https://github.com/monalisa/octo-cat.git/",
+			wantHost:  "github.com",
+			wantOwner: "monalisa",
+			wantRepo:  "octo-cat",
+		},
+		{
+			name:      "percent-encoded segments",
+			input:     "https://example.com/mona%2Dlisa/octo%20cat.git",
+			wantHost:  "example.com",
+			wantOwner: "mona-lisa",
+			wantRepo:  "octo cat",
+		},
+		{
+			name:       "encoded slash",
+			input:      "https://example.com/monalisa%2Focto-cat/repo",
+			wantReason: RepoURLInvalidSegment,
+			wantErr:    true,
+		},
+		{
+			name:       "missing owner",
+			input:      "https://github.com/",
+			wantReason: RepoURLMissingOwner,
+			wantErr:    true,
+		},
+		{
+			name:       "missing name",
+			input:      "https://github.com/monalisa",
+			wantReason: RepoURLMissingName,
+			wantErr:    true,
+		},
+		{
+			name:       "empty name",
+			input:      "https://github.com/monalisa/.git",
+			wantReason: RepoURLMissingName,
+			wantErr:    true,
+		},
+		{
+			name:       "empty segment",
+			input:      "https://github.com/monalisa//octo-cat",
+			wantReason: RepoURLMissingName,
+			wantErr:    true,
+		},
+		{
+			name:       "no hostname",
+			input:      "/monalisa/octo-cat",
+			wantReason: RepoURLNoHostname,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.input)
+			assert.NoError(t, err)
+			host, owner, repo, err := RepoInfoFromURLWithOptions(u, tt.opts)
+			if tt.wantErr {
+				var urlErr *RepoURLError
+				if assert.ErrorAs(t, err, &urlErr) {
+					assert.Equal(t, tt.wantReason, urlErr.Reason)
+					assert.Contains(t, urlErr.Error(), tt.wantReason.String())
+					assert.Equal(t, u, urlErr.URL)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHost, host)
+			assert.Equal(t, tt.wantOwner, owner)
+			assert.Equal(t, tt.wantRepo, repo)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// GitHub rejects app JWTs that expire more than 10 minutes in the future.
+	appJWTLifetime = 9 * time.Minute
+	// Backdate the issued at time to allow for clock drift.
+	appJWTClockSkew = 60 * time.Second
+	// Refresh installation tokens this long before they expire.
+	installationTokenRefreshWindow = 5 * time.Minute
+)
+
+// AppInstallation holds the credentials used to authenticate as an
+// installation of a GitHub App.
+type AppInstallation struct {
+	// AppID is the ID or the client ID of the GitHub App.
+	AppID string
+
+	// InstallationID is the ID of the installation of the GitHub App
+	// that access tokens will be requested for.
+	InstallationID int64
+
+	// PrivateKey is a PEM encoded private key of the GitHub App,
+	// in either PKCS #1 or PKCS #8 form.
+	PrivateKey []byte
+}
+
+// JWT signs a JSON Web Token that authenticates as the GitHub App.
+// The token is valid for nine minutes.
+func (a *AppInstallation) JWT() (string, error) {
+	return a.signJWT(time.Now())
+}
+
+func (a *AppInstallation) signJWT(now time.Time) (string, error) {
+	if a.AppID == "" {
+		return "", errors.New("app ID is required to sign a GitHub App JWT")
+	}
+	key, err := parseRSAPrivateKey(a.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": a.AppID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode GitHub App private key: no PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("failed to parse GitHub App private key: not an RSA key")
+	}
+	return key, nil
+}
+
+// installationTokenSource exchanges GitHub App JWTs for installation access
+// tokens and caches them until shortly before they expire.
+type installationTokenSource struct {
+	app    *AppInstallation
+	client *http.Client
+	host   string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+
+	now func() time.Time
+}
+
+func newInstallationTokenSource(app *AppInstallation, host string, rt http.RoundTripper) *installationTokenSource {
+	return &installationTokenSource{
+		app:    app,
+		client: &http.Client{Transport: rt},
+		host:   host,
+	}
+}
+
+func (s *installationTokenSource) timeNow() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// Token returns a cached installation access token,
+// requesting a new one if it is missing or about to expire.
+// Installation access tokens are only valid for the host
+// the token source was created for.
+func (s *installationTokenSource) Token(ctx context.Context, _ string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeNow()
+	if s.token != "" && now.Add(installationTokenRefreshWindow).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	jwt, err := s.app.signJWT(now)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", restPrefix(s.host), s.app.InstallationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(accept, "application/vnd.github+json")
+	req.Header.Set(authorization, fmt.Sprintf("Bearer %s", jwt))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", HandleHTTPError(resp)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", err
+	}
+	if result.Token == "" {
+		return "", errors.New("installation access token missing from response")
+	}
+
+	s.token = result.Token
+	s.expiresAt = result.ExpiresAt
+	return s.token, nil
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppInstallationJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		app        AppInstallation
+		wantErrMsg string
+	}{
+		{
+			name: "PKCS #1 private key",
+			app: AppInstallation{
+				AppID:      "123",
+				PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+			},
+		},
+		{
+			name: "PKCS #8 private key",
+			app: AppInstallation{
+				AppID:      "Iv1.abc",
+				PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+			},
+		},
+		{
+			name:       "invalid private key",
+			app:        AppInstallation{AppID: "123", PrivateKey: []byte("not a key")},
+			wantErrMsg: "failed to decode GitHub App private key: no PEM data found",
+		},
+		{
+			name:       "missing app ID",
+			app:        AppInstallation{},
+			wantErrMsg: "app ID is required to sign a GitHub App JWT",
+		},
+	}
+
+	now := time.Unix(1700000000, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwt, err := tt.app.signJWT(now)
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			claims := verifyJWT(t, &key.PublicKey, jwt)
+			assert.Equal(t, tt.app.AppID, claims["iss"])
+			assert.Equal(t, float64(now.Unix()-60), claims["iat"])
+			assert.Equal(t, float64(now.Unix()+540), claims["exp"])
+		})
+	}
+}
+
+func TestAppInstallationClient(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	app := &AppInstallation{
+		AppID:          "123",
+		InstallationID: 42,
+		PrivateKey:     pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}
+
+	tests := []struct {
+		name         string
+		host         string
+		wantTokenURL string
+		wantURL      string
+	}{
+		{
+			name:         "github.com",
+			host:         "github.com",
+			wantTokenURL: "https://api.github.com/app/installations/42/access_tokens",
+			wantURL:      "https://api.github.com/repos/OWNER/REPO",
+		},
+		{
+			name:         "enterprise",
+			host:         "enterprise.com",
+			wantTokenURL: "https://enterprise.com/api/v3/app/installations/42/access_tokens",
+			wantURL:      "https://enterprise.com/api/v3/repos/OWNER/REPO",
+		},
+		{
+			name:         "tenancy",
+			host:         "tenant.ghe.com",
+			wantTokenURL: "https://api.tenant.ghe.com/app/installations/42/access_tokens",
+			wantURL:      "https://api.tenant.ghe.com/repos/OWNER/REPO",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokenRequests int
+			now := time.Now()
+			tr := tripper{
+				roundTrip: func(req *http.Request) (*http.Response, error) {
+					body := `{}`
+					switch req.URL.String() {
+					case tt.wantTokenURL:
+						tokenRequests++
+						assert.Equal(t, http.MethodPost, req.Method)
+						jwt := strings.TrimPrefix(req.Header.Get(authorization), "Bearer ")
+						verifyJWT(t, &key.PublicKey, jwt)
+						expiresAt := now.Add(time.Hour).UTC().Format(time.RFC3339)
+						body = fmt.Sprintf(`{"token": "ghs_%d", "expires_at": %q}`, tokenRequests, expiresAt)
+					case tt.wantURL:
+						assert.Equal(t, fmt.Sprintf("token ghs_%d", tokenRequests), req.Header.Get(authorization))
+					default:
+						assert.Empty(t, req.Header.Get(authorization))
+					}
+					return &http.Response{
+						StatusCode: 200,
+						Header:     http.Header{contentType: []string{jsonContentType}},
+						Body:       io.NopCloser(bytes.NewBufferString(body)),
+						Request:    req,
+					}, nil
+				},
+			}
+
+			client, err := NewRESTClient(ClientOptions{
+				Host:            tt.host,
+				AppInstallation: app,
+				Transport:       tr,
+			})
+			assert.NoError(t, err)
+
+			assert.NoError(t, client.Get("repos/OWNER/REPO", nil))
+			assert.NoError(t, client.Get("repos/OWNER/REPO", nil))
+			assert.NoError(t, client.Get("https://example.com/other", nil))
+			assert.Equal(t, 1, tokenRequests)
+		})
+	}
+}
+
+func TestInstallationTokenSourceRefresh(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	app := &AppInstallation{
+		AppID:          "123",
+		InstallationID: 42,
+		PrivateKey:     pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}
+
+	var tokenRequests int
+	start := time.Unix(1700000000, 0)
+	tr := tripper{
+		roundTrip: func(req *http.Request) (*http.Response, error) {
+			tokenRequests++
+			body := fmt.Sprintf(`{"token": "ghs_%d", "expires_at": %q}`, tokenRequests, start.Add(time.Hour).UTC().Format(time.RFC3339))
+			return &http.Response{
+				StatusCode: 201,
+				Header:     http.Header{contentType: []string{jsonContentType}},
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Request:    req,
+			}, nil
+		},
+	}
+
+	source := newInstallationTokenSource(app, "github.com", tr)
+	now := start
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background(), "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_1", token)
+
+	now = start.Add(50 * time.Minute)
+	token, err = source.Token(context.Background(), "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_1", token)
+
+	now = start.Add(56 * time.Minute)
+	token, err = source.Token(context.Background(), "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_2", token)
+}
+
+func verifyJWT(t *testing.T, key *rsa.PublicKey, jwt string) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	assert.Len(t, parts, 3)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig))
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assert.NoError(t, err)
+	claims := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(b, &claims))
+	return claims
+}
@@ -13,8 +13,15 @@ import (
 
 // ClientOptions holds available options to configure API clients.
 type ClientOptions struct {
+	// AppInstallation specifies GitHub App credentials used to authenticate
+	// as an installation of the app. Installation access tokens are requested
+	// as needed and refreshed before they expire.
+	// AuthToken and TokenSource take precedence over AppInstallation.
+	AppInstallation *AppInstallation
+
 	// AuthToken is the authorization token that will be used
 	// to authenticate against API endpoints.
+	// AuthToken takes precedence over TokenSource if both are specified.
 	AuthToken string
 
 	// CacheDir is the directory to use for cached API requests.
@@ -60,6 +67,12 @@ type ClientOptions struct {
 	// Default is no timeout.
 	Timeout time.Duration
 
+	// TokenSource provides the authorization token used to authenticate
+	// against API endpoints. Unlike AuthToken it is consulted for every request,
+	// allowing tokens to be rotated or fetched lazily.
+	// Default is to use AuthToken.
+	TokenSource TokenSource
+
 	// Transport specifies the mechanism by which individual API requests are made.
 	// If both Transport and UnixDomainSocket are specified then Transport takes
 	// precedence. Due to this behavior any value set for Transport needs to manually
@@ -68,6 +81,12 @@ type ClientOptions struct {
 	// Default is http.DefaultTransport.
 	Transport http.RoundTripper
 
+	// User specifies the login of the account to authenticate as when
+	// resolving the auth token for Host, for hosts with multiple accounts.
+	// It has no effect if AuthToken or TokenSource are specified.
+	// Default is the active account.
+	User string
+
 	// UnixDomainSocket specifies the Unix domain socket address by which individual
 	// API requests will be routed. If specifed, this will form the base of the API
 	// request transport chain.
@@ -79,7 +98,7 @@ func optionsNeedResolution(opts ClientOptions) bool {
 	if opts.Host == "" {
 		return true
 	}
-	if opts.AuthToken == "" {
+	if opts.AuthToken == "" && opts.TokenSource == nil && opts.AppInstallation == nil {
 		return true
 	}
 	if opts.UnixDomainSocket == "" && opts.Transport == nil {
@@ -93,10 +112,17 @@ func resolveOptions(opts ClientOptions) (ClientOptions, error) {
 	if opts.Host == "" {
 		opts.Host, _ = auth.DefaultHost()
 	}
-	if opts.AuthToken == "" {
-		opts.AuthToken, _ = auth.TokenForHost(opts.Host)
-		if opts.AuthToken == "" {
-			return ClientOptions{}, fmt.Errorf("authentication token not found for host %s", opts.Host)
+	if opts.AuthToken == "" && opts.TokenSource == nil && opts.AppInstallation == nil {
+		if opts.User != "" {
+			opts.AuthToken, _ = auth.TokenForUser(opts.Host, opts.User)
+			if opts.AuthToken == "" {
+				return ClientOptions{}, fmt.Errorf("authentication token not found for user %s on host %s", opts.User, opts.Host)
+			}
+		} else {
+			opts.AuthToken, _ = auth.TokenForHost(opts.Host)
+			if opts.AuthToken == "" {
+				return ClientOptions{}, fmt.Errorf("authentication token not found for host %s", opts.Host)
+			}
 		}
 	}
 	if opts.UnixDomainSocket == "" && cfg != nil {
@@ -48,6 +48,33 @@ func TestResolveOptions(t *testing.T) {
 	}
 }
 
+func TestResolveOptionsUser(t *testing.T) {
+	stubConfig(t, `
+hosts:
+  github.com:
+    users:
+      monalisa:
+        oauth_token: monalisa_token
+      hubot:
+        oauth_token: hubot_token
+    user: monalisa
+    oauth_token: monalisa_token
+`)
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	opts, err := resolveOptions(ClientOptions{Host: "github.com", User: "hubot"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hubot_token", opts.AuthToken)
+
+	opts, err = resolveOptions(ClientOptions{Host: "github.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "monalisa_token", opts.AuthToken)
+
+	_, err = resolveOptions(ClientOptions{Host: "github.com", User: "nobody"})
+	assert.EqualError(t, err, "authentication token not found for user nobody on host github.com")
+}
+
 func TestOptionsNeedResolution(t *testing.T) {
 	tests := []struct {
 		name string
@@ -72,6 +99,24 @@ func TestOptionsNeedResolution(t *testing.T) {
 			},
 			out: false,
 		},
+		{
+			name: "Host, AppInstallation, and Transport specified",
+			opts: ClientOptions{
+				Host:            "test.com",
+				AppInstallation: &AppInstallation{},
+				Transport:       http.DefaultTransport,
+			},
+			out: false,
+		},
+		{
+			name: "Host, TokenSource, and Transport specified",
+			opts: ClientOptions{
+				Host:        "test.com",
+				TokenSource: AuthTokenSource(),
+				Transport:   http.DefaultTransport,
+			},
+			out: false,
+		},
 		{
 			name: "Host, and AuthToken specified",
 			opts: ClientOptions{
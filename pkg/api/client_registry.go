@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/cli/go-gh/v2/internal/git"
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/cli/go-gh/v2/pkg/repository"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// ClientRegistry builds API clients for multiple hosts and caches them per host,
+// for tools that talk to several GitHub instances within the same process.
+// It is safe for concurrent use.
+type ClientRegistry struct {
+	entries map[string]*registryEntry
+	mu      sync.Mutex
+	opts    ClientOptions
+}
+
+// registryEntry holds the clients of a host. Building them may require spawning
+// a gh subprocess to resolve the token, so each host is built once, without
+// holding the lock of the registry, and concurrent lookups of the host share it.
+type registryEntry struct {
+	once    sync.Once
+	clients *hostClients
+	err     error
+}
+
+type hostClients struct {
+	graphQL *GraphQLClient
+	rest    *RESTClient
+}
+
+// NewClientRegistry initializes a ClientRegistry. The opts argument is used as the
+// base configuration for every client built by the registry, with Host set to the
+// host of the client. As AuthToken and AppInstallation are only valid for a single
+// host they are ignored, and tokens are resolved for each host using auth.TokenForHost
+// unless opts.TokenSource is specified.
+func NewClientRegistry(opts ClientOptions) *ClientRegistry {
+	opts.AuthToken = ""
+	opts.AppInstallation = nil
+	return &ClientRegistry{
+		entries: map[string]*registryEntry{},
+		opts:    opts,
+	}
+}
+
+// RESTClient returns a client to send requests to the REST API of the specified host.
+func (r *ClientRegistry) RESTClient(host string) (*RESTClient, error) {
+	c, err := r.clientsForHost(host)
+	if err != nil {
+		return nil, err
+	}
+	return c.rest, nil
+}
+
+// GraphQLClient returns a client to send requests to the GraphQL API of the specified host.
+func (r *ClientRegistry) GraphQLClient(host string) (*GraphQLClient, error) {
+	c, err := r.clientsForHost(host)
+	if err != nil {
+		return nil, err
+	}
+	return c.graphQL, nil
+}
+
+// RESTClientForRepo returns a client to send requests to the REST API of the host of repo.
+func (r *ClientRegistry) RESTClientForRepo(repo repository.Repository) (*RESTClient, error) {
+	return r.RESTClient(repo.Host)
+}
+
+// GraphQLClientForRepo returns a client to send requests to the GraphQL API of the host of repo.
+func (r *ClientRegistry) GraphQLClientForRepo(repo repository.Repository) (*GraphQLClient, error) {
+	return r.GraphQLClient(repo.Host)
+}
+
+// RESTClientForURL returns a client to send requests to the REST API of the host of
+// the specified URL. Web, API and git remote URLs are supported.
+func (r *ClientRegistry) RESTClientForURL(u string) (*RESTClient, error) {
+	host, err := hostFromURL(u)
+	if err != nil {
+		return nil, err
+	}
+	return r.RESTClient(host)
+}
+
+// GraphQLClientForURL returns a client to send requests to the GraphQL API of the host of
+// the specified URL. Web, API and git remote URLs are supported.
+func (r *ClientRegistry) GraphQLClientForURL(u string) (*GraphQLClient, error) {
+	host, err := hostFromURL(u)
+	if err != nil {
+		return nil, err
+	}
+	return r.GraphQLClient(host)
+}
+
+// RepositoryParent returns the parent of the specified repository using the REST API,
+// and false if the repository is not a fork. It implements repository.ForkResolver.
+func (r *ClientRegistry) RepositoryParent(ctx context.Context, repo repository.Repository) (repository.Repository, bool, error) {
+	client, err := r.RESTClientForRepo(repo)
+	if err != nil {
+		return repository.Repository{}, false, err
+	}
+	var resp struct {
+		Parent *struct {
+			Name  string
+			Owner struct {
+				Login string
+			}
+		}
+	}
+	path := fmt.Sprintf("repos/%s/%s", url.PathEscape(repo.Owner), url.PathEscape(repo.Name))
+	if err := client.DoWithContext(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return repository.Repository{}, false, err
+	}
+	if resp.Parent == nil {
+		return repository.Repository{}, false, nil
+	}
+	return repository.Repository{Host: repo.Host, Owner: resp.Parent.Owner.Login, Name: resp.Parent.Name}, true, nil
+}
+
+func (r *ClientRegistry) clientsForHost(host string) (*hostClients, error) {
+	if host == "" {
+		return nil, fmt.Errorf("host is required to build an API client")
+	}
+	// Aliases such as api.github.com share the clients of the host they belong to.
+	key := auth.NewHost(host).Name()
+
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &registryEntry{}
+		r.entries[key] = entry
+	}
+	r.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.clients, entry.err = r.newClients(key)
+	})
+	if entry.err != nil {
+		// Failures are not cached, so that the next lookup tries again.
+		r.mu.Lock()
+		if r.entries[key] == entry {
+			delete(r.entries, key)
+		}
+		r.mu.Unlock()
+		return nil, entry.err
+	}
+	return entry.clients, nil
+}
+
+func (r *ClientRegistry) newClients(host string) (*hostClients, error) {
+	opts := r.opts
+	opts.Host = host
+	if opts.Headers != nil {
+		// Each client adds its own default and authorization headers.
+		headers := make(map[string]string, len(opts.Headers))
+		for k, v := range opts.Headers {
+			headers[k] = v
+		}
+		opts.Headers = headers
+	}
+	if optionsNeedResolution(opts) {
+		var err error
+		opts, err = resolveOptions(opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	httpClient, err := NewHTTPClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newHostClients(opts.Host, httpClient), nil
+}
+
+func newHostClients(host string, httpClient *http.Client) *hostClients {
+	endpoint := graphQLEndpoint(host)
+	return &hostClients{
+		rest: &RESTClient{
+			client: httpClient,
+			host:   host,
+		},
+		graphQL: &GraphQLClient{
+			client:     graphql.NewClient(endpoint, httpClient),
+			host:       endpoint,
+			httpClient: httpClient,
+		},
+	}
+}
+
+func hostFromURL(s string) (string, error) {
+	u, err := git.ParseURL(s)
+	if err != nil {
+		return "", err
+	}
+	host := u.Hostname()
+	if host == "" {
+		return "", fmt.Errorf("no hostname detected in %q", s)
+	}
+	// Map API hosts such as api.github.com back to the host they belong to.
+	return auth.NewHost(host).Name(), nil
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRegistry(t *testing.T) {
+	stubConfig(t, `
+hosts:
+  github.com:
+    oauth_token: dotcom_token
+  ghe.io:
+    oauth_token: ghe_token
+  other.ghe.io:
+    oauth_token: other_token
+`)
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_ENTERPRISE_TOKEN", "")
+
+	var requests []string
+	tr := tripper{
+		roundTrip: func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.URL.String()+" "+req.Header.Get(authorization))
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{contentType: []string{jsonContentType}},
+				Body:       io.NopCloser(bytes.NewBufferString("{}")),
+				Request:    req,
+			}, nil
+		},
+	}
+
+	registry := NewClientRegistry(ClientOptions{
+		AuthToken:    "ignored",
+		Transport:    tr,
+		LogIgnoreEnv: true,
+	})
+
+	dotcom, err := registry.RESTClientForRepo(repository.Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"})
+	assert.NoError(t, err)
+	assert.NoError(t, dotcom.Get("repos/OWNER/REPO", nil))
+
+	ghe, err := registry.RESTClientForURL("git@ghe.io:OWNER/REPO.git")
+	assert.NoError(t, err)
+	assert.NoError(t, ghe.Get("repos/OWNER/REPO", nil))
+
+	other, err := registry.GraphQLClientForURL("https://OTHER.ghe.io/OWNER/REPO/pull/1")
+	assert.NoError(t, err)
+	assert.NoError(t, other.Do("query { viewer { login } }", nil, nil))
+
+	assert.Equal(t, []string{
+		"https://api.github.com/repos/OWNER/REPO token dotcom_token",
+		"https://ghe.io/api/v3/repos/OWNER/REPO token ghe_token",
+		"https://other.ghe.io/api/graphql token other_token",
+	}, requests)
+
+	cached, err := registry.RESTClient("GitHub.com")
+	assert.NoError(t, err)
+	assert.Same(t, dotcom, cached)
+
+	fromAPIURL, err := registry.RESTClientForURL("https://api.github.com/repos/OWNER/REPO")
+	assert.NoError(t, err)
+	assert.Same(t, dotcom, fromAPIURL)
+
+	fromAPIHost, err := registry.RESTClient("api.github.com")
+	assert.NoError(t, err)
+	assert.Same(t, dotcom, fromAPIHost)
+
+	_, err = registry.GraphQLClient("unknown.io")
+	assert.EqualError(t, err, "authentication token not found for host unknown.io")
+
+	_, err = registry.RESTClientForURL("OWNER/REPO")
+	assert.EqualError(t, err, `no hostname detected in "OWNER/REPO"`)
+}
+
+func TestClientRegistryRepositoryParent(t *testing.T) {
+	var _ repository.ForkResolver = (*ClientRegistry)(nil)
+
+	tr := tripper{
+		roundTrip: func(req *http.Request) (*http.Response, error) {
+			body := `{"name":"REPO","fork":false}`
+			if req.URL.Path == "/api/v3/repos/monalisa/REPO" {
+				body = `{"name":"REPO","fork":true,"parent":{"name":"REPO","owner":{"login":"OWNER"}}}`
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{contentType: []string{jsonContentType}},
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Request:    req,
+			}, nil
+		},
+	}
+	registry := NewClientRegistry(ClientOptions{
+		TokenSource:  TokenSourceFunc(func(context.Context, string) (string, error) { return "token", nil }),
+		Transport:    tr,
+		LogIgnoreEnv: true,
+	})
+
+	parent, isFork, err := registry.RepositoryParent(context.Background(), repository.Repository{Host: "ghe.io", Owner: "monalisa", Name: "REPO"})
+	assert.NoError(t, err)
+	assert.True(t, isFork)
+	assert.Equal(t, repository.Repository{Host: "ghe.io", Owner: "OWNER", Name: "REPO"}, parent)
+
+	_, isFork, err = registry.RepositoryParent(context.Background(), repository.Repository{Host: "ghe.io", Owner: "OWNER", Name: "REPO"})
+	assert.NoError(t, err)
+	assert.False(t, isFork)
+}
+
+func TestClientRegistryConcurrentHosts(t *testing.T) {
+	stubConfig(t, `
+hosts:
+  github.com:
+    oauth_token: dotcom_token
+`)
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_ENTERPRISE_TOKEN", "")
+
+	registry := NewClientRegistry(ClientOptions{
+		Transport:    tripper{},
+		LogIgnoreEnv: true,
+	})
+
+	// Block the construction of the clients of a host, as a hanging token lookup would.
+	slow := &registryEntry{}
+	registry.entries["slow.io"] = slow
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go slow.once.Do(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	defer close(release)
+
+	done := make(chan error)
+	go func() {
+		_, err := registry.RESTClient("github.com")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookup of github.com blocked by lookup of slow.io")
+	}
+
+	// Failures are not cached.
+	_, err := registry.RESTClient("ghe.io")
+	assert.EqualError(t, err, "authentication token not found for host ghe.io")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghe_token")
+	_, err = registry.RESTClient("ghe.io")
+	assert.NoError(t, err)
+}
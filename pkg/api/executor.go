@@ -0,0 +1,363 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultConcurrency = 4
+	rateLimitRemaining = "X-RateLimit-Remaining"
+	rateLimitReset     = "X-RateLimit-Reset"
+	rateLimitResource  = "X-RateLimit-Resource"
+	retryAfter         = "Retry-After"
+
+	defaultRateLimitResource = "core"
+)
+
+// Executor runs API requests concurrently using a bounded pool of workers.
+// All workers share the rate limit budgets, which the API tracks separately
+// for each resource such as REST and GraphQL. Once the API reports that the
+// budget of a resource is exhausted, no worker issues further requests for that
+// resource until the budget resets, and once it asks clients to back off, no
+// worker issues further requests at all for the requested time.
+type Executor struct {
+	concurrency int
+	graphQL     *GraphQLClient
+	rest        *RESTClient
+}
+
+// ExecutorOptions holds available options to configure an Executor.
+type ExecutorOptions struct {
+	// ClientOptions are the options used to build the REST and GraphQL
+	// clients that requests are issued with.
+	ClientOptions ClientOptions
+
+	// Concurrency is the maximum number of requests in flight at any time.
+	// GitHub recommends against making many concurrent requests, so keep this small.
+	// Default is 4.
+	Concurrency int
+}
+
+// ExecutorRequest is a single unit of work run by an Executor.
+type ExecutorRequest interface {
+	// Execute issues the request using the clients provided by the Executor.
+	Execute(ctx context.Context, rest *RESTClient, graphQL *GraphQLClient) error
+}
+
+// RESTRequest is an ExecutorRequest that issues a REST API request.
+// The response is populated into the Response field.
+type RESTRequest struct {
+	Method   string
+	Path     string
+	Body     io.Reader
+	Response interface{}
+}
+
+// Execute issues the REST API request.
+func (r *RESTRequest) Execute(ctx context.Context, rest *RESTClient, _ *GraphQLClient) error {
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return rest.DoWithContext(ctx, method, r.Path, r.Body, r.Response)
+}
+
+func (r *RESTRequest) String() string {
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return fmt.Sprintf("%s %s", method, r.Path)
+}
+
+// GraphQLRequest is an ExecutorRequest that issues a GraphQL query request.
+// The response is populated into the Response field.
+type GraphQLRequest struct {
+	Query     string
+	Variables map[string]interface{}
+	Response  interface{}
+}
+
+// Execute issues the GraphQL query request.
+func (r *GraphQLRequest) Execute(ctx context.Context, _ *RESTClient, graphQL *GraphQLClient) error {
+	return graphQL.DoWithContext(ctx, r.Query, r.Variables, r.Response)
+}
+
+func (r *GraphQLRequest) String() string {
+	return "GraphQL query"
+}
+
+// ExecutorError aggregates the errors of the requests that failed during a
+// single Executor run. Errors are ordered by the index of their request.
+type ExecutorError struct {
+	Errors []*ExecutorItemError
+}
+
+// Allow ExecutorError to satisfy error interface.
+func (e *ExecutorError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, item := range e.Errors {
+		msgs = append(msgs, item.Error())
+	}
+	return fmt.Sprintf("%d of the requests failed:\n%s", len(e.Errors), strings.Join(msgs, "\n"))
+}
+
+// ExecutorItemError is the error of a single request run by an Executor.
+type ExecutorItemError struct {
+	Index   int
+	Request ExecutorRequest
+	Err     error
+}
+
+// Allow ExecutorItemError to satisfy error interface.
+func (e *ExecutorItemError) Error() string {
+	if s, ok := e.Request.(fmt.Stringer); ok {
+		return fmt.Sprintf("request %d (%s): %v", e.Index, s, e.Err)
+	}
+	return fmt.Sprintf("request %d: %v", e.Index, e.Err)
+}
+
+func (e *ExecutorItemError) Unwrap() error {
+	return e.Err
+}
+
+// NewExecutor builds an Executor whose requests are sent to the host
+// specified in opts.ClientOptions. Host and auth token resolution happens
+// the same way as for NewRESTClient and NewGraphQLClient.
+func NewExecutor(opts ExecutorOptions) (*Executor, error) {
+	clientOpts := opts.ClientOptions
+	if optionsNeedResolution(clientOpts) {
+		var err error
+		clientOpts, err = resolveOptions(clientOpts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	httpClient, err := NewHTTPClient(clientOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := newRateLimiter()
+	httpClient.Transport = limiter.RoundTripper(httpClient.Transport)
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	clients := newHostClients(clientOpts.Host, httpClient)
+
+	return &Executor{
+		concurrency: concurrency,
+		graphQL:     clients.graphQL,
+		rest:        clients.rest,
+	}, nil
+}
+
+// Run executes the requests concurrently and blocks until all of them have completed.
+// Responses are populated into the requests themselves, so their order always
+// matches the order of reqs regardless of completion order.
+// If any requests fail, an *ExecutorError attributing each error to its request
+// is returned. Requests not yet started when ctx is cancelled fail with ctx.Err().
+func (e *Executor) Run(ctx context.Context, reqs []ExecutorRequest) error {
+	var mu sync.Mutex
+	var itemErrs []*ExecutorItemError
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	workers := e.concurrency
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				err := ctx.Err()
+				if err == nil {
+					err = reqs[i].Execute(ctx, e.rest, e.graphQL)
+				}
+				if err != nil {
+					mu.Lock()
+					itemErrs = append(itemErrs, &ExecutorItemError{Index: i, Request: reqs[i], Err: err})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range reqs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if len(itemErrs) == 0 {
+		return nil
+	}
+	sort.Slice(itemErrs, func(i, j int) bool {
+		return itemErrs[i].Index < itemErrs[j].Index
+	})
+	return &ExecutorError{Errors: itemErrs}
+}
+
+// rateLimiter tracks the rate limit budgets reported by the API
+// and pauses requests while they are exhausted.
+type rateLimiter struct {
+	mu       sync.Mutex
+	budgets  map[string]*rateLimitBudget
+	resumeAt time.Time
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// rateLimitBudget is the budget of a single rate limit resource.
+// A negative remaining value means the budget is unknown.
+type rateLimitBudget struct {
+	remaining int
+	reset     time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{budgets: map[string]*rateLimitBudget{}}
+}
+
+func (rl *rateLimiter) RoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return rateLimitRoundTripper{limiter: rl, rt: rt}
+}
+
+// budget returns the budget of resource. The caller must hold rl.mu.
+func (rl *rateLimiter) budget(resource string) *rateLimitBudget {
+	b, ok := rl.budgets[resource]
+	if !ok {
+		b = &rateLimitBudget{remaining: -1}
+		rl.budgets[resource] = b
+	}
+	return b
+}
+
+// wait blocks until the rate limit budget of resource allows another request to be made.
+func (rl *rateLimiter) wait(ctx context.Context, resource string) error {
+	for {
+		rl.mu.Lock()
+		b := rl.budget(resource)
+		now := rl.timeNow()
+		var d time.Duration
+		if now.Before(rl.resumeAt) {
+			d = rl.resumeAt.Sub(now)
+		} else if b.remaining == 0 && now.Before(b.reset) {
+			d = b.reset.Sub(now)
+		}
+		if d == 0 {
+			if b.remaining == 0 {
+				// The budget has been reset. Allow requests until the API reports otherwise.
+				b.remaining = -1
+			} else if b.remaining > 0 {
+				b.remaining--
+			}
+			rl.mu.Unlock()
+			return nil
+		}
+		rl.mu.Unlock()
+		if err := rl.doSleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// update records the rate limit budget reported in a response.
+func (rl *rateLimiter) update(resp *http.Response) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	remaining, remainingErr := strconv.Atoi(resp.Header.Get(rateLimitRemaining))
+	reset, resetErr := strconv.ParseInt(resp.Header.Get(rateLimitReset), 10, 64)
+	if remainingErr == nil && resetErr == nil {
+		resource := resp.Header.Get(rateLimitResource)
+		if resource == "" {
+			resource = rateLimitResourceFor(resp.Request)
+		}
+		b := rl.budget(resource)
+		resetTime := time.Unix(reset, 0)
+		// Responses to requests made in parallel arrive out of order, so within
+		// the same window only ever lower the budget that has been handed out.
+		if !resetTime.Equal(b.reset) || b.remaining < 0 || remaining < b.remaining {
+			b.remaining = remaining
+		}
+		b.reset = resetTime
+	}
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.Atoi(resp.Header.Get(retryAfter)); err == nil {
+			if t := rl.timeNow().Add(time.Duration(seconds) * time.Second); t.After(rl.resumeAt) {
+				rl.resumeAt = t
+			}
+		}
+	}
+}
+
+// rateLimitResourceFor returns the rate limit resource that req counts against,
+// as reported by the API in the X-RateLimit-Resource header of the response.
+func rateLimitResourceFor(req *http.Request) string {
+	if req == nil || req.URL == nil {
+		return defaultRateLimitResource
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/api/v3")
+	switch {
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	case strings.HasPrefix(path, "/search/code"):
+		return "code_search"
+	case strings.HasPrefix(path, "/search/"):
+		return "search"
+	}
+	return defaultRateLimitResource
+}
+
+func (rl *rateLimiter) timeNow() time.Time {
+	if rl.now != nil {
+		return rl.now()
+	}
+	return time.Now()
+}
+
+func (rl *rateLimiter) doSleep(ctx context.Context, d time.Duration) error {
+	if rl.sleep != nil {
+		return rl.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type rateLimitRoundTripper struct {
+	limiter *rateLimiter
+	rt      http.RoundTripper
+}
+
+func (rrt rateLimitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := rrt.limiter.wait(req.Context(), rateLimitResourceFor(req)); err != nil {
+		return nil, err
+	}
+	resp, err := rrt.rt.RoundTrip(req)
+	if err == nil {
+		rrt.limiter.update(resp)
+	}
+	return resp, err
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorRun(t *testing.T) {
+	var inFlight, maxInFlight int32
+	tr := tripper{
+		roundTrip: func(req *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			status := 200
+			body := fmt.Sprintf(`{"path": %q}`, req.URL.Path)
+			if req.URL.Path == "/repos/OWNER/REPO3" {
+				status = 404
+				body = `{"message": "Not Found"}`
+			}
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{contentType: []string{jsonContentType}},
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Request:    req,
+			}, nil
+		},
+	}
+
+	executor, err := NewExecutor(ExecutorOptions{
+		ClientOptions: ClientOptions{
+			Host:      "github.com",
+			AuthToken: "token",
+			Transport: tr,
+		},
+		Concurrency: 2,
+	})
+	assert.NoError(t, err)
+
+	responses := make([]struct{ Path string }, 6)
+	reqs := make([]ExecutorRequest, len(responses))
+	for i := range responses {
+		reqs[i] = &RESTRequest{Path: fmt.Sprintf("repos/OWNER/REPO%d", i), Response: &responses[i]}
+	}
+
+	err = executor.Run(context.Background(), reqs)
+	var execErr *ExecutorError
+	assert.True(t, errors.As(err, &execErr))
+	assert.Len(t, execErr.Errors, 1)
+	assert.Equal(t, 3, execErr.Errors[0].Index)
+	assert.EqualError(t, execErr.Errors[0], "request 3 (GET repos/OWNER/REPO3): HTTP 404: Not Found (https://api.github.com/repos/OWNER/REPO3)")
+	var httpErr *HTTPError
+	assert.True(t, errors.As(execErr.Errors[0], &httpErr))
+
+	for i, res := range responses {
+		if i == 3 {
+			assert.Equal(t, "", res.Path)
+			continue
+		}
+		assert.Equal(t, fmt.Sprintf("/repos/OWNER/REPO%d", i), res.Path)
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestExecutorRunCancelled(t *testing.T) {
+	executor, err := NewExecutor(ExecutorOptions{
+		ClientOptions: ClientOptions{
+			Host:      "github.com",
+			AuthToken: "token",
+			Transport: tripper{roundTrip: func(req *http.Request) (*http.Response, error) {
+				t.Fatal("unexpected request")
+				return nil, nil
+			}},
+		},
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = executor.Run(ctx, []ExecutorRequest{&RESTRequest{Path: "a"}, &GraphQLRequest{Query: "query { b }"}})
+	var execErr *ExecutorError
+	assert.True(t, errors.As(err, &execErr))
+	assert.Len(t, execErr.Errors, 2)
+	assert.EqualError(t, execErr.Errors[1], "request 1 (GraphQL query): context canceled")
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var slept []time.Duration
+	var mu sync.Mutex
+	rl := newRateLimiter()
+	rl.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	rl.sleep = func(_ context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		slept = append(slept, d)
+		now = now.Add(d)
+		return nil
+	}
+
+	respond := func(remaining int, reset int64, status int, headers ...string) *http.Response {
+		h := http.Header{}
+		h.Set(rateLimitRemaining, strconv.Itoa(remaining))
+		h.Set(rateLimitReset, strconv.FormatInt(reset, 10))
+		for i := 0; i < len(headers); i += 2 {
+			h.Set(headers[i], headers[i+1])
+		}
+		return &http.Response{StatusCode: status, Header: h}
+	}
+
+	assert.NoError(t, rl.wait(context.Background(), "core"))
+	rl.update(respond(2, 1060, 200))
+	assert.NoError(t, rl.wait(context.Background(), "core"))
+	assert.NoError(t, rl.wait(context.Background(), "core"))
+	assert.Empty(t, slept)
+
+	// The budget is exhausted, so the next request waits for the reset.
+	assert.NoError(t, rl.wait(context.Background(), "core"))
+	assert.Equal(t, []time.Duration{60 * time.Second}, slept)
+
+	// Secondary rate limits pause requests for the time requested by the API.
+	rl.update(respond(5, 1120, 403, retryAfter, "30"))
+	assert.NoError(t, rl.wait(context.Background(), "core"))
+	assert.Equal(t, []time.Duration{60 * time.Second, 30 * time.Second}, slept)
+
+	rl.sleep = func(ctx context.Context, _ time.Duration) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	rl.update(respond(0, 2000, 200))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, rl.wait(ctx, "core"), context.Canceled)
+}
+
+func TestRateLimiterResources(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var slept []time.Duration
+	rl := newRateLimiter()
+	rl.now = func() time.Time { return now }
+	rl.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		now = now.Add(d)
+		return nil
+	}
+
+	budgets := map[string][2]string{
+		"core":    {"100", "1030"},
+		"graphql": {"0", "1060"},
+	}
+	rt := rl.RoundTripper(tripper{roundTrip: func(req *http.Request) (*http.Response, error) {
+		resource := rateLimitResourceFor(req)
+		h := http.Header{}
+		h.Set(rateLimitRemaining, budgets[resource][0])
+		h.Set(rateLimitReset, budgets[resource][1])
+		if resource == "core" {
+			// The resource is inferred from the request if the header is missing.
+			h.Set(rateLimitResource, resource)
+		}
+		return &http.Response{StatusCode: 200, Header: h, Request: req}, nil
+	}})
+	send := func(path string) {
+		req, err := http.NewRequest("GET", "https://api.github.com"+path, nil)
+		assert.NoError(t, err)
+		_, err = rt.RoundTrip(req)
+		assert.NoError(t, err)
+	}
+
+	// An exhausted GraphQL budget does not pause REST requests.
+	send("/graphql")
+	send("/repos/OWNER/REPO")
+	send("/repos/OWNER/REPO")
+	assert.Empty(t, slept)
+
+	// A REST response with a different reset time does not replenish the GraphQL budget.
+	send("/graphql")
+	assert.Equal(t, []time.Duration{60 * time.Second}, slept)
+}
+
+func TestRateLimitResourceFor(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://api.github.com/repos/OWNER/REPO", want: "core"},
+		{url: "https://api.github.com/repos/search/REPO", want: "core"},
+		{url: "https://api.github.com/graphql", want: "graphql"},
+		{url: "https://ghe.example.com/api/graphql", want: "graphql"},
+		{url: "https://api.github.com/search/issues?q=bug", want: "search"},
+		{url: "https://ghe.example.com/api/v3/search/code?q=main", want: "code_search"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.url, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, rateLimitResourceFor(req))
+		})
+	}
+}
@@ -5,10 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
-	"strings"
 
 	"github.com/cli/go-gh/v2/pkg/auth"
 	graphql "github.com/cli/shurcooL-graphql"
@@ -169,15 +167,5 @@ type graphQLResponse struct {
 }
 
 func graphQLEndpoint(host string) string {
-	if isGarage(host) {
-		return fmt.Sprintf("https://%s/api/graphql", host)
-	}
-	host = auth.NormalizeHostname(host)
-	if auth.IsEnterprise(host) {
-		return fmt.Sprintf("https://%s/api/graphql", host)
-	}
-	if strings.EqualFold(host, localhost) {
-		return fmt.Sprintf("http://api.%s/graphql", host)
-	}
-	return fmt.Sprintf("https://api.%s/graphql", host)
+	return auth.NewHost(host).GraphQLURL()
 }
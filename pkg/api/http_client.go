@@ -25,7 +25,6 @@ const (
 	contentType     = "Content-Type"
 	github          = "github.com"
 	jsonContentType = "application/json; charset=utf-8"
-	localhost       = "github.localhost"
 	modulePath      = "github.com/cli/go-gh"
 	timeZone        = "Time-Zone"
 	userAgent       = "User-Agent"
@@ -113,7 +112,11 @@ func NewHTTPClient(opts ClientOptions) (*http.Client, error) {
 	if !opts.SkipDefaultHeaders {
 		resolveHeaders(opts.Headers)
 	}
-	transport = newHeaderRoundTripper(opts.Host, opts.AuthToken, opts.Headers, transport)
+	tokenSource := opts.TokenSource
+	if tokenSource == nil && opts.AppInstallation != nil {
+		tokenSource = newInstallationTokenSource(opts.AppInstallation, opts.Host, transport)
+	}
+	transport = newHeaderRoundTripper(opts.Host, opts.AuthToken, tokenSource, opts.Headers, transport)
 
 	return &http.Client{Transport: transport, Timeout: opts.Timeout}, nil
 }
@@ -130,14 +133,11 @@ func isSameDomain(requestHost, domain string) bool {
 	return (requestHost == domain) || strings.HasSuffix(requestHost, "."+domain)
 }
 
-func isGarage(host string) bool {
-	return strings.EqualFold(host, "garage.github.com")
-}
-
 type headerRoundTripper struct {
-	headers map[string]string
-	host    string
-	rt      http.RoundTripper
+	headers     map[string]string
+	host        string
+	rt          http.RoundTripper
+	tokenSource TokenSource
 }
 
 func resolveHeaders(headers map[string]string) {
@@ -171,14 +171,17 @@ func resolveHeaders(headers map[string]string) {
 	}
 }
 
-func newHeaderRoundTripper(host string, authToken string, headers map[string]string, rt http.RoundTripper) http.RoundTripper {
+func newHeaderRoundTripper(host string, authToken string, tokenSource TokenSource, headers map[string]string, rt http.RoundTripper) http.RoundTripper {
 	if _, ok := headers[authorization]; !ok && authToken != "" {
 		headers[authorization] = fmt.Sprintf("token %s", authToken)
 	}
-	if len(headers) == 0 {
+	if _, ok := headers[authorization]; ok {
+		tokenSource = nil
+	}
+	if len(headers) == 0 && tokenSource == nil {
 		return rt
 	}
-	return headerRoundTripper{host: host, headers: headers, rt: rt}
+	return headerRoundTripper{host: host, headers: headers, rt: rt, tokenSource: tokenSource}
 }
 
 func (hrt headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -196,6 +199,18 @@ func (hrt headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 		}
 	}
 
+	// Tokens from a token source are resolved for every request, but
+	// are subject to the same host restriction as a static token.
+	if hrt.tokenSource != nil && req.Header.Get(authorization) == "" && isSameDomain(req.URL.Hostname(), hrt.host) {
+		token, err := hrt.tokenSource.Token(req.Context(), hrt.host)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set(authorization, fmt.Sprintf("token %s", token))
+		}
+	}
+
 	return hrt.rt.RoundTrip(req)
 }
 
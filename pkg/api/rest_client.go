@@ -3,7 +3,6 @@ package api
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -158,15 +157,5 @@ func restURL(hostname string, pathOrURL string) string {
 }
 
 func restPrefix(hostname string) string {
-	if isGarage(hostname) {
-		return fmt.Sprintf("https://%s/api/v3/", hostname)
-	}
-	hostname = auth.NormalizeHostname(hostname)
-	if auth.IsEnterprise(hostname) {
-		return fmt.Sprintf("https://%s/api/v3/", hostname)
-	}
-	if strings.EqualFold(hostname, localhost) {
-		return fmt.Sprintf("http://api.%s/", hostname)
-	}
-	return fmt.Sprintf("https://api.%s/", hostname)
+	return auth.NewHost(hostname).RESTURL()
 }
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// TokenSource provides authentication tokens for API requests.
+// Implementations must be safe for concurrent use.
+type TokenSource interface {
+	// Token returns the token used to authenticate against the specified host.
+	Token(ctx context.Context, host string) (string, error)
+}
+
+// TokenSourceFunc is an adapter to allow the use of ordinary functions as a TokenSource.
+type TokenSourceFunc func(ctx context.Context, host string) (string, error)
+
+// Token calls f(ctx, host).
+func (f TokenSourceFunc) Token(ctx context.Context, host string) (string, error) {
+	return f(ctx, host)
+}
+
+// AuthTokenSource returns a TokenSource that resolves tokens using auth.TokenForHost
+// at the time of each request, rather than once when the client is created.
+func AuthTokenSource() TokenSource {
+	return TokenSourceFunc(func(_ context.Context, host string) (string, error) {
+		token, _ := auth.TokenForHost(host)
+		if token == "" {
+			return "", fmt.Errorf("authentication token not found for host %s", host)
+		}
+		return token, nil
+	})
+}
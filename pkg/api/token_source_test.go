@@ -0,0 +1,117 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenSource(t *testing.T) {
+	var authHeaders []string
+	reflectHTTP := tripper{
+		roundTrip: func(req *http.Request) (*http.Response, error) {
+			authHeaders = append(authHeaders, req.Header.Get(authorization))
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewBufferString("{}")),
+				Request:    req,
+			}, nil
+		},
+	}
+
+	var calls int
+	var hosts []string
+	source := TokenSourceFunc(func(_ context.Context, host string) (string, error) {
+		calls++
+		hosts = append(hosts, host)
+		if calls == 3 {
+			return "", errors.New("secret manager unavailable")
+		}
+		return fmt.Sprintf("rotated_%d", calls), nil
+	})
+
+	client, err := NewHTTPClient(ClientOptions{
+		Host:         "github.com",
+		TokenSource:  source,
+		Transport:    reflectHTTP,
+		LogIgnoreEnv: true,
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Get("https://api.github.com/user")
+	assert.NoError(t, err)
+	_, err = client.Get("https://api.github.com/user")
+	assert.NoError(t, err)
+	_, err = client.Get("https://example.com/user")
+	assert.NoError(t, err)
+	_, err = client.Get("https://api.github.com/user")
+	assert.EqualError(t, err, `Get "https://api.github.com/user": secret manager unavailable`)
+
+	assert.Equal(t, []string{"token rotated_1", "token rotated_2", ""}, authHeaders)
+	assert.Equal(t, []string{"github.com", "github.com", "github.com"}, hosts)
+}
+
+func TestTokenSourcePrecedence(t *testing.T) {
+	reflectHTTP := tripper{
+		roundTrip: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     req.Header.Clone(),
+				Body:       io.NopCloser(bytes.NewBufferString("{}")),
+				Request:    req,
+			}, nil
+		},
+	}
+	source := TokenSourceFunc(func(_ context.Context, _ string) (string, error) {
+		return "from_source", nil
+	})
+
+	tests := []struct {
+		name     string
+		opts     ClientOptions
+		wantAuth string
+	}{
+		{
+			name:     "token source",
+			opts:     ClientOptions{TokenSource: source},
+			wantAuth: "token from_source",
+		},
+		{
+			name:     "auth token takes precedence",
+			opts:     ClientOptions{AuthToken: "static", TokenSource: source},
+			wantAuth: "token static",
+		},
+		{
+			name:     "authorization header takes precedence",
+			opts:     ClientOptions{Headers: map[string]string{authorization: "Bearer header"}, TokenSource: source},
+			wantAuth: "Bearer header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Host = "github.com"
+			tt.opts.Transport = reflectHTTP
+			tt.opts.LogIgnoreEnv = true
+			client, err := NewHTTPClient(tt.opts)
+			assert.NoError(t, err)
+			res, err := client.Get("https://api.github.com/user")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAuth, res.Header.Get(authorization))
+		})
+	}
+}
+
+func TestAuthTokenSource(t *testing.T) {
+	t.Setenv("GH_TOKEN", "env_token")
+	token, err := AuthTokenSource().Token(context.Background(), "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "env_token", token)
+}
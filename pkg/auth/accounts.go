@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"github.com/cli/go-gh/v2/pkg/config"
+)
+
+// AccountsForHost retrieves the logins of the accounts that are authenticated
+// for the specified host in the configuration file.
+// Returns an empty string slice if no accounts are found.
+func AccountsForHost(host string) []string {
+	cfg, _ := config.Read(nil)
+	return accountsForHost(cfg, host)
+}
+
+func accountsForHost(cfg *config.Config, host string) []string {
+	if cfg == nil {
+		return []string{}
+	}
+	host = NormalizeHostname(host)
+	users, err := cfg.Keys([]string{hostsKey, host, usersKey})
+	if err == nil && len(users) > 0 {
+		return users
+	}
+	// Configuration files written before multiple accounts were
+	// supported only record the active account.
+	if user, _ := cfg.Get([]string{hostsKey, host, userKey}); user != "" {
+		return []string{user}
+	}
+	return []string{}
+}
+
+// ActiveUser retrieves the login of the active account for the specified host
+// and the source of that login.
+// Returns "", "default" if no active account is found.
+func ActiveUser(host string) (string, string) {
+	cfg, _ := config.Read(nil)
+	return activeUser(cfg, host)
+}
+
+func activeUser(cfg *config.Config, host string) (string, string) {
+	if cfg != nil {
+		host = NormalizeHostname(host)
+		if user, _ := cfg.Get([]string{hostsKey, host, userKey}); user != "" {
+			return user, userKey
+		}
+	}
+	return "", defaultSource
+}
+
+// TokenForUser retrieves an authentication token for a specific account on the specified
+// host and the source of that token. Unlike TokenForHost, environment variables are not
+// consulted as they are not associated with an account. The token is read from the
+// configuration file, falling back to the system keyring as described for TokenForHost.
+//
+// Returns "", "default" if no applicable token is found.
+func TokenForUser(host, user string) (string, string) {
+	cfg, _ := config.Read(nil)
+	if token, source := tokenForUser(cfg, host, user); token != "" {
+		return token, source
+	}
+
+	if token, source := cachedTokenFromSecureStorage(host, user); token != "" {
+		return token, source
+	}
+
+	return "", defaultSource
+}
+
+func tokenForUser(cfg *config.Config, host, user string) (string, string) {
+	if cfg == nil {
+		return "", defaultSource
+	}
+	host = NormalizeHostname(host)
+	if token, _ := cfg.Get([]string{hostsKey, host, usersKey, user, oauthToken}); token != "" {
+		return token, oauthToken
+	}
+	// The token of the active account is also stored at the top level of the host entry.
+	if active, _ := activeUser(cfg, host); active == user {
+		if token, _ := cfg.Get([]string{hostsKey, host, oauthToken}); token != "" {
+			return token, oauthToken
+		}
+	}
+	return "", defaultSource
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountsForHost(t *testing.T) {
+	tests := []struct {
+		name   string
+		host   string
+		config *config.Config
+		want   []string
+	}{
+		{
+			name:   "no config",
+			host:   "github.com",
+			config: nil,
+			want:   []string{},
+		},
+		{
+			name:   "multiple accounts",
+			host:   "github.com",
+			config: testMultiAccountConfig(),
+			want:   []string{"monalisa", "hubot", "octocat"},
+		},
+		{
+			name:   "single account in legacy config",
+			host:   "enterprise.com",
+			config: testHostsConfig(),
+			want:   []string{"user2"},
+		},
+		{
+			name:   "unknown host",
+			host:   "unknown.com",
+			config: testMultiAccountConfig(),
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, accountsForHost(tt.config, tt.host))
+		})
+	}
+}
+
+func TestActiveUser(t *testing.T) {
+	user, source := activeUser(testMultiAccountConfig(), "github.com")
+	assert.Equal(t, "monalisa", user)
+	assert.Equal(t, "user", source)
+
+	user, source = activeUser(testMultiAccountConfig(), "unknown.com")
+	assert.Equal(t, "", user)
+	assert.Equal(t, "default", source)
+}
+
+func TestTokenForUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		user       string
+		config     *config.Config
+		wantToken  string
+		wantSource string
+	}{
+		{
+			name:       "token for non-active user",
+			host:       "github.com",
+			user:       "hubot",
+			config:     testMultiAccountConfig(),
+			wantToken:  "hubot_token",
+			wantSource: "oauth_token",
+		},
+		{
+			name:       "token for active user",
+			host:       "github.com",
+			user:       "monalisa",
+			config:     testMultiAccountConfig(),
+			wantToken:  "monalisa_token",
+			wantSource: "oauth_token",
+		},
+		{
+			name:       "token for active user in legacy config",
+			host:       "enterprise.com",
+			user:       "user2",
+			config:     testHostsConfig(),
+			wantToken:  "yyyyyyyyyyyyyyyyyyyy",
+			wantSource: "oauth_token",
+		},
+		{
+			name:       "token for user in secure storage",
+			host:       "github.com",
+			user:       "octocat",
+			config:     testMultiAccountConfig(),
+			wantToken:  "",
+			wantSource: "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GH_TOKEN", "env_token")
+			token, source := tokenForUser(tt.config, tt.host, tt.user)
+			assert.Equal(t, tt.wantToken, token)
+			assert.Equal(t, tt.wantSource, source)
+		})
+	}
+}
+
+func testMultiAccountConfig() *config.Config {
+	var data = `
+hosts:
+  github.com:
+    git_protocol: https
+    users:
+      monalisa:
+      hubot:
+        oauth_token: hubot_token
+      octocat:
+    user: monalisa
+    oauth_token: monalisa_token
+`
+	return config.ReadFromString(data)
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"strconv"
 	"strings"
 
 	"github.com/cli/go-gh/v2/internal/set"
@@ -27,11 +26,16 @@ const (
 	localhost             = "github.localhost"
 	oauthToken            = "oauth_token"
 	tenancyHost           = "ghe.com" // TenancyHost is the domain suffix of a tenancy GitHub instance.
+	userKey               = "user"
+	usersKey              = "users"
 )
 
 // TokenForHost retrieves an authentication token and the source of that token for the specified
 // host. The source can be either an environment variable, configuration file, or the system
-// keyring. In the latter case, this shells out to "gh auth token" to obtain the token.
+// keyring. In the latter case, this shells out to "gh auth token" to obtain the token, unless
+// a keyring has been configured with SetKeyring. Tokens read from the system keyring are
+// cached for the lifetime of the process, see SetTokenCacheEnabled. This includes lookups
+// that find no token, so a token stored later is not returned until InvalidateToken is called.
 //
 // Returns "", "default" if no applicable token is found.
 func TokenForHost(host string) (string, string) {
@@ -39,15 +43,8 @@ func TokenForHost(host string) (string, string) {
 		return token, source
 	}
 
-	ghExe := os.Getenv("GH_PATH")
-	if ghExe == "" {
-		ghExe, _ = safeexec.LookPath("gh")
-	}
-
-	if ghExe != "" {
-		if token, source := tokenFromGh(ghExe, host); token != "" {
-			return token, source
-		}
+	if token, source := cachedTokenFromSecureStorage(host, ""); token != "" {
+		return token, source
 	}
 
 	return "", defaultSource
@@ -62,39 +59,24 @@ func TokenFromEnvOrConfig(host string) (string, string) {
 }
 
 func tokenForHost(cfg *config.Config, host string) (string, string) {
-	host = NormalizeHostname(host)
-	if IsEnterprise(host) {
-		if token := os.Getenv(ghEnterpriseToken); token != "" {
-			return token, ghEnterpriseToken
-		}
-		if token := os.Getenv(githubEnterpriseToken); token != "" {
-			return token, githubEnterpriseToken
-		}
-		if isCodespaces, _ := strconv.ParseBool(os.Getenv(codespaces)); isCodespaces {
-			if token := os.Getenv(githubToken); token != "" {
-				return token, githubToken
-			}
-		}
-		if cfg != nil {
-			token, _ := cfg.Get([]string{hostsKey, host, oauthToken})
-			return token, oauthToken
-		}
-	}
-	if token := os.Getenv(ghToken); token != "" {
-		return token, ghToken
-	}
-	if token := os.Getenv(githubToken); token != "" {
-		return token, githubToken
-	}
-	if cfg != nil {
-		token, _ := cfg.Get([]string{hostsKey, host, oauthToken})
-		return token, oauthToken
+	token, res := resolveToken(cfg, host)
+	return token, res.Source
+}
+
+func ghPath() string {
+	ghExe := os.Getenv("GH_PATH")
+	if ghExe == "" {
+		ghExe, _ = safeexec.LookPath("gh")
 	}
-	return "", defaultSource
+	return ghExe
 }
 
-func tokenFromGh(path string, host string) (string, string) {
-	cmd := exec.Command(path, "auth", "token", "--secure-storage", "--hostname", host)
+func tokenFromGh(path string, host string, user string) (string, string) {
+	args := []string{"auth", "token", "--secure-storage", "--hostname", host}
+	if user != "" {
+		args = append(args, "--user", user)
+	}
+	cmd := exec.Command(path, args...)
 	result, err := cmd.Output()
 	if err != nil {
 		return "", "gh"
@@ -153,15 +135,13 @@ func defaultHost(cfg *config.Config) (string, string) {
 // IsEnterprise determines if a provided host is a GitHub Enterprise Server instance,
 // rather than GitHub.com or a tenancy GitHub instance.
 func IsEnterprise(host string) bool {
-	normalizedHost := NormalizeHostname(host)
-	return normalizedHost != github && normalizedHost != localhost && !IsTenancy(normalizedHost)
+	return NewHost(host).Kind() == HostKindEnterprise
 }
 
 // IsTenancy determines if a provided host is a tenancy GitHub instance,
 // rather than GitHub.com or a GitHub Enterprise Server instance.
 func IsTenancy(host string) bool {
-	normalizedHost := NormalizeHostname(host)
-	return strings.HasSuffix(normalizedHost, "."+tenancyHost)
+	return NewHost(host).Kind() == HostKindTenancy
 }
 
 // NormalizeHostname ensures the host matches the values used throughout
@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	deviceCodePath      = "login/device/code"
+	deviceGrantType     = "urn:ietf:params:oauth:grant-type:device_code"
+	slowDownInterval    = 5 * time.Second
+	tokenPath           = "login/oauth/access_token"
+)
+
+// ErrDeviceCodeExpired is returned when the user did not authorize
+// the device before the device code expired.
+var ErrDeviceCodeExpired = errors.New("the device code has expired")
+
+// ErrAccessDenied is returned when the user cancelled the authorization.
+var ErrAccessDenied = errors.New("access denied by the user")
+
+// DeviceFlowOptions holds the options to configure an OAuth device authorization flow.
+type DeviceFlowOptions struct {
+	// ClientID is the client ID of the OAuth or GitHub App doing the authorization.
+	ClientID string
+
+	// Scopes are the OAuth scopes to request.
+	Scopes []string
+
+	// Host is the host to authenticate against.
+	// Default is github.com.
+	Host string
+
+	// BaseURL overrides the URL that login requests are sent to, which is
+	// otherwise derived from Host. This is useful for pointing the flow at a
+	// local stand-in server.
+	BaseURL string
+
+	// HTTPClient is the client used to send login requests.
+	// Default is http.DefaultClient.
+	HTTPClient *http.Client
+
+	// DisplayCode is called with the user code and the verification URI once
+	// a device code has been issued, so that they can be shown to the user.
+	DisplayCode func(userCode, verificationURI string) error
+
+	// Browser, if specified, is used to open the verification URI after
+	// DisplayCode has been called. A *browser.Browser can be used here.
+	Browser interface {
+		Browse(string) error
+	}
+}
+
+// DeviceCode is a device code issued at the start of a device authorization flow.
+type DeviceCode struct {
+	DeviceCode      string
+	UserCode        string
+	VerificationURI string
+	ExpiresIn       time.Duration
+	Interval        time.Duration
+}
+
+// DeviceFlowToken is an access token obtained through a device authorization flow.
+type DeviceFlowToken struct {
+	Token     string
+	TokenType string
+	Scopes    []string
+}
+
+// DeviceFlowError represents an unexpected error returned by the
+// OAuth endpoints during a device authorization flow.
+type DeviceFlowError struct {
+	Code        string
+	Description string
+}
+
+// Allow DeviceFlowError to satisfy error interface.
+func (e *DeviceFlowError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// DeviceFlow runs the complete OAuth device authorization flow. It requests a device
+// code, displays the user code and verification URI, optionally opens the browser, and
+// then polls until the user has authorized the device, returning the access token.
+func DeviceFlow(ctx context.Context, opts DeviceFlowOptions) (*DeviceFlowToken, error) {
+	code, err := RequestDeviceCode(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DisplayCode != nil {
+		if err := opts.DisplayCode(code.UserCode, code.VerificationURI); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Browser != nil {
+		if err := opts.Browser.Browse(code.VerificationURI); err != nil {
+			return nil, err
+		}
+	}
+	return PollDeviceToken(ctx, opts, code)
+}
+
+// RequestDeviceCode starts a device authorization flow by requesting a device code.
+func RequestDeviceCode(ctx context.Context, opts DeviceFlowOptions) (*DeviceCode, error) {
+	form := url.Values{}
+	form.Set("client_id", opts.ClientID)
+	if len(opts.Scopes) > 0 {
+		form.Set("scope", strings.Join(opts.Scopes, " "))
+	}
+
+	var resp struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+		ExpiresIn       int    `json:"expires_in"`
+		Interval        int    `json:"interval"`
+		Error           string `json:"error"`
+		ErrorDesc       string `json:"error_description"`
+	}
+	if err := postForm(ctx, opts, deviceCodePath, form, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, &DeviceFlowError{Code: resp.Error, Description: resp.ErrorDesc}
+	}
+	if resp.DeviceCode == "" {
+		return nil, errors.New("device code missing from response")
+	}
+
+	return &DeviceCode{
+		DeviceCode:      resp.DeviceCode,
+		UserCode:        resp.UserCode,
+		VerificationURI: resp.VerificationURI,
+		ExpiresIn:       time.Duration(resp.ExpiresIn) * time.Second,
+		Interval:        time.Duration(resp.Interval) * time.Second,
+	}, nil
+}
+
+// PollDeviceToken polls for an access token until the user has authorized the device
+// identified by code. Polling backs off when asked to slow down, and stops when the
+// device code expires, the user denies access, or ctx is cancelled.
+func PollDeviceToken(ctx context.Context, opts DeviceFlowOptions, code *DeviceCode) (*DeviceFlowToken, error) {
+	interval := code.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	var deadline time.Time
+	if code.ExpiresIn > 0 {
+		deadline = time.Now().Add(code.ExpiresIn)
+	}
+
+	form := url.Values{}
+	form.Set("client_id", opts.ClientID)
+	form.Set("device_code", code.DeviceCode)
+	form.Set("grant_type", deviceGrantType)
+
+	for {
+		if err := sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return nil, ErrDeviceCodeExpired
+		}
+
+		var resp struct {
+			AccessToken string `json:"access_token"`
+			TokenType   string `json:"token_type"`
+			Scope       string `json:"scope"`
+			Error       string `json:"error"`
+			ErrorDesc   string `json:"error_description"`
+			Interval    int    `json:"interval"`
+		}
+		if err := postForm(ctx, opts, tokenPath, form, &resp); err != nil {
+			return nil, err
+		}
+
+		switch resp.Error {
+		case "":
+			if resp.AccessToken == "" {
+				return nil, errors.New("access token missing from response")
+			}
+			token := &DeviceFlowToken{
+				Token:     resp.AccessToken,
+				TokenType: resp.TokenType,
+			}
+			for _, s := range strings.Split(resp.Scope, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					token.Scopes = append(token.Scopes, s)
+				}
+			}
+			return token, nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			if resp.Interval > 0 {
+				interval = time.Duration(resp.Interval) * time.Second
+			} else {
+				interval += slowDownInterval
+			}
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		case "access_denied":
+			return nil, ErrAccessDenied
+		default:
+			return nil, &DeviceFlowError{Code: resp.Error, Description: resp.ErrorDesc}
+		}
+	}
+}
+
+func postForm(ctx context.Context, opts DeviceFlowOptions, path string, form url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, loginURL(opts)+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// OAuth errors are returned with 200 and 4xx statuses alike,
+	// so only treat responses without a JSON body as failures.
+	if err := json.Unmarshal(body, result); err != nil {
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("HTTP %d (%s)", resp.StatusCode, req.URL)
+		}
+		return err
+	}
+	return nil
+}
+
+func loginURL(opts DeviceFlowOptions) string {
+	if opts.BaseURL != "" {
+		return strings.TrimSuffix(opts.BaseURL, "/") + "/"
+	}
+	host := opts.Host
+	if host == "" {
+		host = github
+	}
+	return NewHost(host).WebURL()
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	var slept []time.Duration
+	stubSleep(t, func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	})
+
+	tests := []struct {
+		name          string
+		tokenReplies  []string
+		wantToken     *DeviceFlowToken
+		wantErr       error
+		wantErrMsg    string
+		wantIntervals []time.Duration
+	}{
+		{
+			name: "authorized after pending and slow down",
+			tokenReplies: []string{
+				`{"error": "authorization_pending"}`,
+				`{"error": "slow_down", "interval": 10}`,
+				`{"error": "slow_down"}`,
+				`{"access_token": "gho_abc", "token_type": "bearer", "scope": "repo,read:org"}`,
+			},
+			wantToken: &DeviceFlowToken{
+				Token:     "gho_abc",
+				TokenType: "bearer",
+				Scopes:    []string{"repo", "read:org"},
+			},
+			wantIntervals: []time.Duration{1 * time.Second, 1 * time.Second, 10 * time.Second, 15 * time.Second},
+		},
+		{
+			name:         "expired device code",
+			tokenReplies: []string{`{"error": "expired_token"}`},
+			wantErr:      ErrDeviceCodeExpired,
+		},
+		{
+			name:         "access denied",
+			tokenReplies: []string{`{"error": "access_denied"}`},
+			wantErr:      ErrAccessDenied,
+		},
+		{
+			name:         "unexpected error",
+			tokenReplies: []string{`{"error": "incorrect_client_credentials", "error_description": "The client_id is not valid."}`},
+			wantErrMsg:   "incorrect_client_credentials: The client_id is not valid.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slept = nil
+			var polls int
+			mux := http.NewServeMux()
+			mux.HandleFunc("/login/device/code", func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+				assert.Equal(t, "CLIENT", r.PostForm.Get("client_id"))
+				assert.Equal(t, "repo read:org", r.PostForm.Get("scope"))
+				fmt.Fprint(w, `{"device_code": "DEVICE", "user_code": "ABCD-1234", "verification_uri": "https://github.com/login/device", "expires_in": 900, "interval": 1}`)
+			})
+			mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+				assert.Equal(t, "DEVICE", r.PostForm.Get("device_code"))
+				assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.PostForm.Get("grant_type"))
+				fmt.Fprint(w, tt.tokenReplies[polls])
+				polls++
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			var displayed []string
+			browser := &stubBrowser{}
+			token, err := DeviceFlow(context.Background(), DeviceFlowOptions{
+				ClientID: "CLIENT",
+				Scopes:   []string{"repo", "read:org"},
+				BaseURL:  server.URL,
+				DisplayCode: func(code, uri string) error {
+					displayed = append(displayed, code, uri)
+					return nil
+				},
+				Browser: browser,
+			})
+
+			assert.Equal(t, []string{"ABCD-1234", "https://github.com/login/device"}, displayed)
+			assert.Equal(t, "https://github.com/login/device", browser.url)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+				return
+			}
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantToken, token)
+			assert.Equal(t, len(tt.tokenReplies), polls)
+			assert.Equal(t, tt.wantIntervals, slept)
+		})
+	}
+}
+
+func TestDeviceFlowCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := PollDeviceToken(ctx, DeviceFlowOptions{BaseURL: "http://127.0.0.1:0"}, &DeviceCode{Interval: time.Hour})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoginURL(t *testing.T) {
+	tests := []struct {
+		opts DeviceFlowOptions
+		want string
+	}{
+		{opts: DeviceFlowOptions{}, want: "https://github.com/"},
+		{opts: DeviceFlowOptions{Host: "ghe.io"}, want: "https://ghe.io/"},
+		{opts: DeviceFlowOptions{Host: "api.tenant.ghe.com"}, want: "https://tenant.ghe.com/"},
+		{opts: DeviceFlowOptions{Host: "github.localhost"}, want: "http://github.localhost/"},
+		{opts: DeviceFlowOptions{Host: "ghe.io", BaseURL: "http://127.0.0.1:8080/"}, want: "http://127.0.0.1:8080/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, loginURL(tt.opts))
+		})
+	}
+}
+
+type stubBrowser struct {
+	url string
+}
+
+func (b *stubBrowser) Browse(url string) error {
+	b.url = url
+	return nil
+}
+
+func stubSleep(t *testing.T, fn func(context.Context, time.Duration) error) {
+	t.Helper()
+	old := sleep
+	sleep = fn
+	t.Cleanup(func() {
+		sleep = old
+	})
+}
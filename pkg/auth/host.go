@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+const garage = "garage.github.com"
+
+// HostKind describes the type of GitHub deployment a host belongs to.
+type HostKind int
+
+const (
+	// HostKindDotcom is GitHub.com.
+	HostKindDotcom HostKind = iota
+	// HostKindEnterprise is a GitHub Enterprise Server instance.
+	HostKindEnterprise
+	// HostKindTenancy is a tenancy GitHub instance hosted under ghe.com.
+	HostKindTenancy
+	// HostKindLocalhost is a local development instance under github.localhost.
+	HostKindLocalhost
+	// HostKindGarage is the garage.github.com staging instance.
+	HostKindGarage
+)
+
+func (k HostKind) String() string {
+	switch k {
+	case HostKindDotcom:
+		return "dotcom"
+	case HostKindEnterprise:
+		return "enterprise"
+	case HostKindTenancy:
+		return "tenancy"
+	case HostKindLocalhost:
+		return "localhost"
+	case HostKindGarage:
+		return "garage"
+	default:
+		return fmt.Sprintf("HostKind(%d)", int(k))
+	}
+}
+
+// Host is a classified GitHub hostname that knows the URLs of the services of its
+// deployment. Use NewHost to create one.
+type Host struct {
+	kind HostKind
+	name string
+}
+
+// NewHost classifies the specified hostname. The hostname is normalized
+// the same way as NormalizeHostname, except that garage.github.com is
+// recognized as its own deployment rather than as GitHub.com.
+func NewHost(hostname string) Host {
+	if strings.EqualFold(hostname, garage) {
+		return Host{kind: HostKindGarage, name: garage}
+	}
+	name := NormalizeHostname(hostname)
+	switch {
+	case name == github:
+		return Host{kind: HostKindDotcom, name: name}
+	case name == localhost:
+		return Host{kind: HostKindLocalhost, name: name}
+	case strings.HasSuffix(name, "."+tenancyHost):
+		return Host{kind: HostKindTenancy, name: name}
+	default:
+		return Host{kind: HostKindEnterprise, name: name}
+	}
+}
+
+// Name returns the normalized hostname.
+func (h Host) Name() string {
+	return h.name
+}
+
+// Kind returns the type of deployment the host belongs to.
+func (h Host) Kind() HostKind {
+	return h.kind
+}
+
+func (h Host) String() string {
+	return h.name
+}
+
+// usesSubdomains reports whether services of the deployment are served from
+// subdomains such as api.github.com rather than from paths of the host.
+func (h Host) usesSubdomains() bool {
+	return h.kind == HostKindDotcom || h.kind == HostKindTenancy || h.kind == HostKindLocalhost
+}
+
+func (h Host) scheme() string {
+	if h.kind == HostKindLocalhost {
+		return "http"
+	}
+	return "https"
+}
+
+// RESTURL returns the base URL of the REST API, including a trailing slash.
+func (h Host) RESTURL() string {
+	if h.usesSubdomains() {
+		return fmt.Sprintf("%s://api.%s/", h.scheme(), h.name)
+	}
+	return fmt.Sprintf("%s://%s/api/v3/", h.scheme(), h.name)
+}
+
+// GraphQLURL returns the URL of the GraphQL API.
+func (h Host) GraphQLURL() string {
+	if h.usesSubdomains() {
+		return fmt.Sprintf("%s://api.%s/graphql", h.scheme(), h.name)
+	}
+	return fmt.Sprintf("%s://%s/api/graphql", h.scheme(), h.name)
+}
+
+// UploadsURL returns the base URL for uploading release assets, including a trailing slash.
+func (h Host) UploadsURL() string {
+	if h.usesSubdomains() {
+		return fmt.Sprintf("%s://uploads.%s/", h.scheme(), h.name)
+	}
+	return fmt.Sprintf("%s://%s/api/uploads/", h.scheme(), h.name)
+}
+
+// WebURL returns the base URL of the web interface, including a trailing slash.
+func (h Host) WebURL() string {
+	return fmt.Sprintf("%s://%s/", h.scheme(), h.name)
+}
+
+// RawURL returns the base URL for raw file contents, including a trailing slash.
+// File contents are available at RawURL() + "OWNER/REPO/REF/PATH".
+func (h Host) RawURL() string {
+	switch h.kind {
+	case HostKindDotcom:
+		return "https://raw.githubusercontent.com/"
+	case HostKindTenancy, HostKindLocalhost:
+		return fmt.Sprintf("%s://raw.%s/", h.scheme(), h.name)
+	default:
+		return fmt.Sprintf("%s://%s/raw/", h.scheme(), h.name)
+	}
+}
+
+// SSHHost returns the hostname to use for git operations over SSH.
+func (h Host) SSHHost() string {
+	return h.name
+}
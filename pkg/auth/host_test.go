@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHost(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		wantName    string
+		wantKind    HostKind
+		wantREST    string
+		wantGraphQL string
+		wantUploads string
+		wantWeb     string
+		wantRaw     string
+	}{
+		{
+			name:        "github",
+			host:        "github.com",
+			wantName:    "github.com",
+			wantKind:    HostKindDotcom,
+			wantREST:    "https://api.github.com/",
+			wantGraphQL: "https://api.github.com/graphql",
+			wantUploads: "https://uploads.github.com/",
+			wantWeb:     "https://github.com/",
+			wantRaw:     "https://raw.githubusercontent.com/",
+		},
+		{
+			name:        "github API",
+			host:        "API.GitHub.com",
+			wantName:    "github.com",
+			wantKind:    HostKindDotcom,
+			wantREST:    "https://api.github.com/",
+			wantGraphQL: "https://api.github.com/graphql",
+			wantUploads: "https://uploads.github.com/",
+			wantWeb:     "https://github.com/",
+			wantRaw:     "https://raw.githubusercontent.com/",
+		},
+		{
+			name:        "enterprise",
+			host:        "mygithub.com",
+			wantName:    "mygithub.com",
+			wantKind:    HostKindEnterprise,
+			wantREST:    "https://mygithub.com/api/v3/",
+			wantGraphQL: "https://mygithub.com/api/graphql",
+			wantUploads: "https://mygithub.com/api/uploads/",
+			wantWeb:     "https://mygithub.com/",
+			wantRaw:     "https://mygithub.com/raw/",
+		},
+		{
+			name:        "tenant API",
+			host:        "api.tenant.ghe.com",
+			wantName:    "tenant.ghe.com",
+			wantKind:    HostKindTenancy,
+			wantREST:    "https://api.tenant.ghe.com/",
+			wantGraphQL: "https://api.tenant.ghe.com/graphql",
+			wantUploads: "https://uploads.tenant.ghe.com/",
+			wantWeb:     "https://tenant.ghe.com/",
+			wantRaw:     "https://raw.tenant.ghe.com/",
+		},
+		{
+			name:        "localhost API",
+			host:        "api.github.localhost",
+			wantName:    "github.localhost",
+			wantKind:    HostKindLocalhost,
+			wantREST:    "http://api.github.localhost/",
+			wantGraphQL: "http://api.github.localhost/graphql",
+			wantUploads: "http://uploads.github.localhost/",
+			wantWeb:     "http://github.localhost/",
+			wantRaw:     "http://raw.github.localhost/",
+		},
+		{
+			name:        "garage",
+			host:        "Garage.GitHub.com",
+			wantName:    "garage.github.com",
+			wantKind:    HostKindGarage,
+			wantREST:    "https://garage.github.com/api/v3/",
+			wantGraphQL: "https://garage.github.com/api/graphql",
+			wantUploads: "https://garage.github.com/api/uploads/",
+			wantWeb:     "https://garage.github.com/",
+			wantRaw:     "https://garage.github.com/raw/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHost(tt.host)
+			assert.Equal(t, tt.wantName, h.Name())
+			assert.Equal(t, tt.wantName, h.SSHHost())
+			assert.Equal(t, tt.wantKind, h.Kind())
+			assert.Equal(t, tt.wantREST, h.RESTURL())
+			assert.Equal(t, tt.wantGraphQL, h.GraphQLURL())
+			assert.Equal(t, tt.wantUploads, h.UploadsURL())
+			assert.Equal(t, tt.wantWeb, h.WebURL())
+			assert.Equal(t, tt.wantRaw, h.RawURL())
+		})
+	}
+}
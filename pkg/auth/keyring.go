@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	keyringSource  = "keyring"
+	keyringTimeout = 3 * time.Second
+)
+
+// ErrKeyringNotFound is returned by a Keyring when no secret is stored
+// for the requested service and user.
+var ErrKeyringNotFound = errors.New("secret not found in keyring")
+
+// ErrKeyringTimeout is returned by the system keyring when it does not respond in time,
+// which can happen when no keyring daemon is running.
+var ErrKeyringTimeout = errors.New("timeout while trying to access the system keyring")
+
+// Keyring is a store of secrets indexed by service and user.
+// Implementations must be safe for concurrent use.
+type Keyring interface {
+	// Get retrieves the secret for service and user.
+	// Returns ErrKeyringNotFound if no secret is stored.
+	Get(service, user string) (string, error)
+
+	// Set stores the secret for service and user.
+	Set(service, user, secret string) error
+
+	// Delete removes the secret for service and user.
+	// Returns ErrKeyringNotFound if no secret is stored.
+	Delete(service, user string) error
+}
+
+var (
+	keyringBackend   Keyring
+	keyringBackendMu sync.RWMutex
+)
+
+// SetKeyring configures TokenForHost and TokenForUser to read tokens that gh stored in
+// secure storage directly from kr, rather than shelling out to "gh auth token".
+// Passing nil restores the default behavior. Cached tokens are invalidated.
+func SetKeyring(kr Keyring) {
+	keyringBackendMu.Lock()
+	keyringBackend = kr
+	keyringBackendMu.Unlock()
+	InvalidateTokens()
+}
+
+func currentKeyring() Keyring {
+	keyringBackendMu.RLock()
+	defer keyringBackendMu.RUnlock()
+	return keyringBackend
+}
+
+// KeyringService returns the keyring service name gh uses to store tokens for host.
+func KeyringService(host string) string {
+	return "gh:" + NormalizeHostname(host)
+}
+
+// TokenFromKeyring retrieves the token gh stored in kr for the specified host and user.
+// If user is empty the token of the active account is retrieved.
+func TokenFromKeyring(kr Keyring, host, user string) (string, error) {
+	return kr.Get(KeyringService(host), user)
+}
+
+// tokenFromSecureStorage retrieves a token from the configured keyring, falling
+// back to shelling out to gh if no keyring has been configured.
+func tokenFromSecureStorage(host, user string) (string, string) {
+	if kr := currentKeyring(); kr != nil {
+		token, _ := TokenFromKeyring(kr, host, user)
+		return token, keyringSource
+	}
+	if ghExe := ghPath(); ghExe != "" {
+		return tokenFromGh(ghExe, host, user)
+	}
+	return "", defaultSource
+}
+
+// NewSystemKeyring returns a Keyring backed by the secure storage of the operating
+// system: the Secret Service over D-Bus on Linux, the Keychain on macOS, and the
+// Credential Manager on Windows. This is the storage gh uses for tokens.
+func NewSystemKeyring() Keyring {
+	return systemKeyring{timeout: keyringTimeout}
+}
+
+type systemKeyring struct {
+	timeout time.Duration
+}
+
+func (k systemKeyring) Get(service, user string) (string, error) {
+	var secret string
+	err := k.withTimeout(func() error {
+		var err error
+		secret, err = keyring.Get(service, user)
+		return err
+	})
+	return secret, err
+}
+
+func (k systemKeyring) Set(service, user, secret string) error {
+	return k.withTimeout(func() error {
+		return keyring.Set(service, user, secret)
+	})
+}
+
+func (k systemKeyring) Delete(service, user string) error {
+	return k.withTimeout(func() error {
+		return keyring.Delete(service, user)
+	})
+}
+
+// withTimeout runs fn, giving up if it does not complete in time as calls
+// to the keyring can block indefinitely when there is no daemon to answer them.
+func (k systemKeyring) withTimeout(fn func() error) error {
+	ch := make(chan error, 1)
+	go func() {
+		ch <- fn()
+	}()
+	select {
+	case err := <-ch:
+		if errors.Is(err, keyring.ErrNotFound) {
+			return ErrKeyringNotFound
+		}
+		return err
+	case <-time.After(k.timeout):
+		return ErrKeyringTimeout
+	}
+}
+
+// NewFileKeyring returns a Keyring that stores secrets unencrypted in a file
+// only readable by the current user. It is intended as a fallback for headless
+// environments where no system keyring is available.
+func NewFileKeyring(path string) Keyring {
+	return &fileKeyring{path: path}
+}
+
+type fileKeyring struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (k *fileKeyring) Get(service, user string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	secrets, err := k.read()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[service][user]
+	if !ok {
+		return "", ErrKeyringNotFound
+	}
+	return secret, nil
+}
+
+func (k *fileKeyring) Set(service, user, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	secrets, err := k.read()
+	if err != nil {
+		return err
+	}
+	if secrets[service] == nil {
+		secrets[service] = map[string]string{}
+	}
+	secrets[service][user] = secret
+	return k.write(secrets)
+}
+
+func (k *fileKeyring) Delete(service, user string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	secrets, err := k.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[service][user]; !ok {
+		return ErrKeyringNotFound
+	}
+	delete(secrets[service], user)
+	if len(secrets[service]) == 0 {
+		delete(secrets, service)
+	}
+	return k.write(secrets)
+}
+
+func (k *fileKeyring) read() (map[string]map[string]string, error) {
+	secrets := map[string]map[string]string{}
+	data, err := os.ReadFile(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring file %s: %w", k.path, err)
+	}
+	return secrets, nil
+}
+
+func (k *fileKeyring) write(secrets map[string]map[string]string) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(k.path, data, 0600)
+}
+
+// NewMemoryKeyring returns a Keyring that keeps secrets in memory,
+// which is useful in tests.
+func NewMemoryKeyring() Keyring {
+	return &memoryKeyring{secrets: map[string]map[string]string{}}
+}
+
+type memoryKeyring struct {
+	mu      sync.RWMutex
+	secrets map[string]map[string]string
+}
+
+func (k *memoryKeyring) Get(service, user string) (string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	secret, ok := k.secrets[service][user]
+	if !ok {
+		return "", ErrKeyringNotFound
+	}
+	return secret, nil
+}
+
+func (k *memoryKeyring) Set(service, user, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.secrets[service] == nil {
+		k.secrets[service] = map[string]string{}
+	}
+	k.secrets[service][user] = secret
+	return nil
+}
+
+func (k *memoryKeyring) Delete(service, user string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.secrets[service][user]; !ok {
+		return ErrKeyringNotFound
+	}
+	delete(k.secrets[service], user)
+	return nil
+}
+
+// NewFallbackKeyring returns a Keyring that uses primary, and falls back to fallback
+// when primary fails, such as when the system keyring is unavailable.
+// Secrets not found in primary are looked up in fallback.
+func NewFallbackKeyring(primary, fallback Keyring) Keyring {
+	return fallbackKeyring{primary: primary, fallback: fallback}
+}
+
+type fallbackKeyring struct {
+	primary  Keyring
+	fallback Keyring
+}
+
+func (k fallbackKeyring) Get(service, user string) (string, error) {
+	secret, err := k.primary.Get(service, user)
+	if err == nil {
+		return secret, nil
+	}
+	return k.fallback.Get(service, user)
+}
+
+func (k fallbackKeyring) Set(service, user, secret string) error {
+	if err := k.primary.Set(service, user, secret); err == nil {
+		return nil
+	}
+	return k.fallback.Set(service, user, secret)
+}
+
+func (k fallbackKeyring) Delete(service, user string) error {
+	primaryErr := k.primary.Delete(service, user)
+	fallbackErr := k.fallback.Delete(service, user)
+	if primaryErr == nil {
+		return nil
+	}
+	return fallbackErr
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyrings(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyring func(t *testing.T) Keyring
+	}{
+		{
+			name: "memory keyring",
+			keyring: func(t *testing.T) Keyring {
+				return NewMemoryKeyring()
+			},
+		},
+		{
+			name: "file keyring",
+			keyring: func(t *testing.T) Keyring {
+				return NewFileKeyring(filepath.Join(t.TempDir(), "nested", "keyring.json"))
+			},
+		},
+		{
+			name: "fallback keyring with unavailable primary",
+			keyring: func(t *testing.T) Keyring {
+				return NewFallbackKeyring(unavailableKeyring{}, NewMemoryKeyring())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kr := tt.keyring(t)
+
+			_, err := kr.Get("gh:github.com", "")
+			assert.ErrorIs(t, err, ErrKeyringNotFound)
+
+			assert.NoError(t, kr.Set("gh:github.com", "", "active_token"))
+			assert.NoError(t, kr.Set("gh:github.com", "monalisa", "monalisa_token"))
+
+			token, err := kr.Get("gh:github.com", "")
+			assert.NoError(t, err)
+			assert.Equal(t, "active_token", token)
+			token, err = kr.Get("gh:github.com", "monalisa")
+			assert.NoError(t, err)
+			assert.Equal(t, "monalisa_token", token)
+
+			assert.NoError(t, kr.Delete("gh:github.com", "monalisa"))
+			_, err = kr.Get("gh:github.com", "monalisa")
+			assert.ErrorIs(t, err, ErrKeyringNotFound)
+			assert.ErrorIs(t, kr.Delete("gh:github.com", "monalisa"), ErrKeyringNotFound)
+		})
+	}
+}
+
+func TestFileKeyringPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	kr := NewFileKeyring(path)
+	assert.NoError(t, kr.Set("gh:github.com", "", "token"))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestSetKeyring(t *testing.T) {
+	for _, k := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "CODESPACES"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_PATH", "/nonexistent/gh")
+
+	kr := NewMemoryKeyring()
+	assert.NoError(t, kr.Set(KeyringService("enterprise.com"), "", "active_token"))
+	assert.NoError(t, kr.Set(KeyringService("enterprise.com"), "hubot", "hubot_token"))
+	SetKeyring(kr)
+	t.Cleanup(func() { SetKeyring(nil) })
+
+	token, source := TokenForHost("enterprise.com")
+	assert.Equal(t, "active_token", token)
+	assert.Equal(t, "keyring", source)
+
+	token, source = TokenForUser("enterprise.com", "hubot")
+	assert.Equal(t, "hubot_token", token)
+	assert.Equal(t, "keyring", source)
+
+	token, source = TokenForHost("other.com")
+	assert.Equal(t, "", token)
+	assert.Equal(t, "default", source)
+
+	res := ExplainTokenForHost("enterprise.com")
+	assert.Equal(t, TokenCandidate{
+		Source:     "keyring",
+		Applicable: true,
+		Checked:    true,
+		Set:        true,
+		Chosen:     true,
+		Cached:     true,
+		Reason:     "chosen from the system keyring by an earlier lookup",
+	}, res.Candidates[len(res.Candidates)-1])
+}
+
+type unavailableKeyring struct{}
+
+func (unavailableKeyring) Get(string, string) (string, error) {
+	return "", errors.New("no keyring daemon")
+}
+
+func (unavailableKeyring) Set(string, string, string) error {
+	return errors.New("no keyring daemon")
+}
+
+func (unavailableKeyring) Delete(string, string) error {
+	return errors.New("no keyring daemon")
+}
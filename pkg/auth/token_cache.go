@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"sync"
+)
+
+// Reading a token from secure storage may require spawning a gh subprocess,
+// so results are cached for the lifetime of the process. Tokens from environment
+// variables and the configuration file are cheap to read and are never cached.
+var tokenCache = struct {
+	mu       sync.Mutex
+	disabled bool
+	entries  map[tokenCacheKey]*cachedToken
+}{
+	entries: map[tokenCacheKey]*cachedToken{},
+}
+
+type tokenCacheKey struct {
+	host string
+	user string
+}
+
+type cachedToken struct {
+	once   sync.Once
+	token  string
+	source string
+}
+
+// SetTokenCacheEnabled enables or disables the in-process cache of tokens read from
+// secure storage by TokenForHost and TokenForUser. The cache is enabled by default.
+// Disabling the cache also clears it.
+func SetTokenCacheEnabled(enabled bool) {
+	tokenCache.mu.Lock()
+	defer tokenCache.mu.Unlock()
+	tokenCache.disabled = !enabled
+	tokenCache.entries = map[tokenCacheKey]*cachedToken{}
+}
+
+// InvalidateToken removes the cached token for the specified host and user,
+// so that the next lookup reads it from secure storage again.
+// An empty user refers to the active account.
+func InvalidateToken(host, user string) {
+	tokenCache.mu.Lock()
+	defer tokenCache.mu.Unlock()
+	delete(tokenCache.entries, tokenCacheKey{host: NormalizeHostname(host), user: user})
+}
+
+// InvalidateTokens removes all cached tokens.
+func InvalidateTokens() {
+	tokenCache.mu.Lock()
+	defer tokenCache.mu.Unlock()
+	tokenCache.entries = map[tokenCacheKey]*cachedToken{}
+}
+
+// cachedTokenFromSecureStorage wraps tokenFromSecureStorage with the token cache.
+// Concurrent lookups of the same host and user share a single read. Lookups that
+// find no token are cached too, as they are just as expensive.
+func cachedTokenFromSecureStorage(host, user string) (string, string) {
+	token, source, _ := lookupTokenCache(host, user)
+	return token, source
+}
+
+// lookupTokenCache is cachedTokenFromSecureStorage that also reports whether the
+// result was read by an earlier lookup rather than from secure storage.
+func lookupTokenCache(host, user string) (token, source string, cached bool) {
+	key := tokenCacheKey{host: NormalizeHostname(host), user: user}
+
+	tokenCache.mu.Lock()
+	if tokenCache.disabled {
+		tokenCache.mu.Unlock()
+		token, source = tokenFromSecureStorage(host, user)
+		return token, source, false
+	}
+	entry, ok := tokenCache.entries[key]
+	if !ok {
+		entry = &cachedToken{}
+		tokenCache.entries[key] = entry
+	}
+	tokenCache.mu.Unlock()
+
+	cached = true
+	entry.once.Do(func() {
+		cached = false
+		entry.token, entry.source = tokenFromSecureStorage(host, user)
+	})
+	return entry.token, entry.source, cached
+}
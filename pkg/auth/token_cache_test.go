@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenCache(t *testing.T) {
+	for _, k := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "CODESPACES"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+
+	kr := &countingKeyring{Keyring: NewMemoryKeyring()}
+	assert.NoError(t, kr.Set(KeyringService("enterprise.com"), "", "token_1"))
+	SetKeyring(kr)
+	t.Cleanup(func() {
+		SetKeyring(nil)
+		SetTokenCacheEnabled(true)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, _ := TokenForHost("enterprise.com")
+			assert.Equal(t, "token_1", token)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), kr.gets.Load())
+
+	// Cached tokens are returned until they are invalidated.
+	assert.NoError(t, kr.Set(KeyringService("enterprise.com"), "", "token_2"))
+	token, _ := TokenForHost("ENTERPRISE.com")
+	assert.Equal(t, "token_1", token)
+	// The trace reports the cached token that TokenForHost returns.
+	candidates := ExplainTokenForHost("enterprise.com").Candidates
+	assert.Equal(t, TokenCandidate{
+		Source:     "keyring",
+		Applicable: true,
+		Checked:    true,
+		Set:        true,
+		Chosen:     true,
+		Cached:     true,
+		Reason:     "chosen from the system keyring by an earlier lookup",
+	}, candidates[len(candidates)-1])
+	InvalidateToken("enterprise.com", "")
+	token, _ = TokenForHost("enterprise.com")
+	assert.Equal(t, "token_2", token)
+	assert.Equal(t, int32(2), kr.gets.Load())
+
+	// Tokens for other users are cached separately.
+	token, _ = TokenForUser("enterprise.com", "hubot")
+	assert.Equal(t, "", token)
+	token, _ = TokenForUser("enterprise.com", "hubot")
+	assert.Equal(t, "", token)
+	assert.Equal(t, int32(3), kr.gets.Load())
+
+	InvalidateTokens()
+	_, _ = TokenForHost("enterprise.com")
+	assert.Equal(t, int32(4), kr.gets.Load())
+
+	SetTokenCacheEnabled(false)
+	_, _ = TokenForHost("enterprise.com")
+	_, _ = TokenForHost("enterprise.com")
+	assert.Equal(t, int32(6), kr.gets.Load())
+}
+
+type countingKeyring struct {
+	Keyring
+	gets atomic.Int32
+}
+
+func (k *countingKeyring) Get(service, user string) (string, error) {
+	k.gets.Add(1)
+	return k.Keyring.Get(service, user)
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+)
+
+// TokenResolution describes how the authentication token for a host was resolved.
+// It does not contain the token itself so that it is safe to display.
+type TokenResolution struct {
+	// Host is the normalized host the token was resolved for.
+	Host string
+
+	// Source is the source of the chosen token, as returned by TokenForHost.
+	Source string
+
+	// Found is true if a token was found.
+	Found bool
+
+	// Candidates are the sources that were considered, in order of precedence.
+	Candidates []TokenCandidate
+}
+
+// TokenCandidate describes a single source considered while resolving a token.
+type TokenCandidate struct {
+	// Source is the name of the environment variable, "oauth_token" for
+	// the configuration file, and "gh" or "keyring" for the system keyring
+	// depending on whether a keyring has been configured with SetKeyring.
+	Source string
+
+	// Applicable is false if the source is never used for the host.
+	Applicable bool
+
+	// Checked is false if the source was not inspected, which only happens
+	// for the system keyring once a token has already been found.
+	Checked bool
+
+	// Set is true if the source contained a token.
+	Set bool
+
+	// Chosen is true if the token from this source was used.
+	Chosen bool
+
+	// Cached is true if the result for the system keyring was read by an earlier
+	// lookup and taken from the token cache, see InvalidateToken.
+	Cached bool
+
+	// Reason explains why the source was chosen or skipped.
+	Reason string
+}
+
+// ExplainTokenForHost returns the full, ordered resolution trace of TokenForHost for the
+// specified host: every candidate source, whether it contained a token, and why it was
+// chosen or skipped. This is intended for diagnostic output. Like TokenForHost, it reads the
+// system keyring through the token cache, so it reports the token TokenForHost returns.
+func ExplainTokenForHost(host string) TokenResolution {
+	cfg, _ := config.Read(nil)
+	_, res := resolveToken(cfg, host)
+
+	secure := TokenCandidate{Source: "gh", Applicable: true}
+	if currentKeyring() != nil {
+		secure.Source = keyringSource
+	}
+	switch {
+	case res.Found:
+		secure.Reason = fmt.Sprintf("not checked because a token was found in %s", res.Source)
+	case secure.Source == "gh" && ghPath() == "":
+		secure.Checked = true
+		secure.Reason = "gh executable not found"
+	default:
+		secure.Checked = true
+		token, source, cached := lookupTokenCache(res.Host, "")
+		secure.Cached = cached
+		if token != "" {
+			secure.Set = true
+			secure.Chosen = true
+			secure.Reason = "chosen from the system keyring"
+			res.Found = true
+			res.Source = source
+		} else {
+			secure.Reason = "no token found in the system keyring"
+		}
+		if cached {
+			secure.Reason += " by an earlier lookup"
+		}
+	}
+	res.Candidates = append(res.Candidates, secure)
+	return res
+}
+
+type tokenResolver struct {
+	token string
+	res   TokenResolution
+}
+
+// consider records a candidate source and chooses its token if it is
+// applicable and no token of higher precedence has been found.
+func (r *tokenResolver) consider(source, token string, applicable bool, notApplicableReason string) {
+	c := TokenCandidate{Source: source, Applicable: applicable, Checked: applicable, Set: token != ""}
+	switch {
+	case !applicable:
+		c.Reason = notApplicableReason
+	case token == "":
+		c.Reason = "not set"
+	case r.res.Found:
+		c.Reason = fmt.Sprintf("ignored because %s takes precedence", r.res.Source)
+	default:
+		c.Chosen = true
+		c.Reason = "chosen"
+		r.token = token
+		r.res.Found = true
+		r.res.Source = source
+	}
+	r.res.Candidates = append(r.res.Candidates, c)
+}
+
+// resolveToken resolves the token for host from environment variables and the
+// configuration file, recording every candidate source that was considered.
+func resolveToken(cfg *config.Config, host string) (string, TokenResolution) {
+	host = NormalizeHostname(host)
+	r := tokenResolver{res: TokenResolution{Host: host}}
+
+	configToken := ""
+	if cfg != nil {
+		configToken, _ = cfg.Get([]string{hostsKey, host, oauthToken})
+	}
+	noConfigReason := "configuration file could not be read"
+
+	if IsEnterprise(host) {
+		r.consider(ghEnterpriseToken, os.Getenv(ghEnterpriseToken), true, "")
+		r.consider(githubEnterpriseToken, os.Getenv(githubEnterpriseToken), true, "")
+		isCodespaces, _ := strconv.ParseBool(os.Getenv(codespaces))
+		r.consider(githubToken, os.Getenv(githubToken), isCodespaces,
+			"only used for GitHub Enterprise Server hosts when running in Codespaces")
+		r.consider(oauthToken, configToken, cfg != nil, noConfigReason)
+		notEnterpriseReason := "only used for GitHub Enterprise Server hosts when the configuration file can not be read"
+		r.consider(ghToken, os.Getenv(ghToken), cfg == nil, notEnterpriseReason)
+		r.consider(githubToken, os.Getenv(githubToken), cfg == nil, notEnterpriseReason)
+	} else {
+		enterpriseOnlyReason := "only used for GitHub Enterprise Server hosts"
+		r.consider(ghEnterpriseToken, os.Getenv(ghEnterpriseToken), false, enterpriseOnlyReason)
+		r.consider(githubEnterpriseToken, os.Getenv(githubEnterpriseToken), false, enterpriseOnlyReason)
+		r.consider(ghToken, os.Getenv(ghToken), true, "")
+		r.consider(githubToken, os.Getenv(githubToken), true, "")
+		r.consider(oauthToken, configToken, cfg != nil, noConfigReason)
+	}
+
+	if !r.res.Found {
+		if cfg != nil {
+			r.res.Source = oauthToken
+		} else {
+			r.res.Source = defaultSource
+		}
+	}
+	return r.token, r.res
+}
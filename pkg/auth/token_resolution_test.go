@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		host           string
+		env            map[string]string
+		config         *config.Config
+		wantToken      string
+		wantResolution TokenResolution
+	}{
+		{
+			name:      "github.com with GH_TOKEN shadowing config token",
+			host:      "github.com",
+			env:       map[string]string{"GH_TOKEN": "gh_token", "GH_ENTERPRISE_TOKEN": "ghe_token"},
+			config:    testHostsConfig(),
+			wantToken: "gh_token",
+			wantResolution: TokenResolution{
+				Host:   "github.com",
+				Source: "GH_TOKEN",
+				Found:  true,
+				Candidates: []TokenCandidate{
+					{Source: "GH_ENTERPRISE_TOKEN", Set: true, Reason: "only used for GitHub Enterprise Server hosts"},
+					{Source: "GITHUB_ENTERPRISE_TOKEN", Reason: "only used for GitHub Enterprise Server hosts"},
+					{Source: "GH_TOKEN", Applicable: true, Checked: true, Set: true, Chosen: true, Reason: "chosen"},
+					{Source: "GITHUB_TOKEN", Applicable: true, Checked: true, Reason: "not set"},
+					{Source: "oauth_token", Applicable: true, Checked: true, Set: true, Reason: "ignored because GH_TOKEN takes precedence"},
+				},
+			},
+		},
+		{
+			name:      "enterprise in Codespaces",
+			host:      "enterprise.com",
+			env:       map[string]string{"CODESPACES": "true", "GITHUB_TOKEN": "github_token", "GH_TOKEN": "gh_token"},
+			config:    testHostsConfig(),
+			wantToken: "github_token",
+			wantResolution: TokenResolution{
+				Host:   "enterprise.com",
+				Source: "GITHUB_TOKEN",
+				Found:  true,
+				Candidates: []TokenCandidate{
+					{Source: "GH_ENTERPRISE_TOKEN", Applicable: true, Checked: true, Reason: "not set"},
+					{Source: "GITHUB_ENTERPRISE_TOKEN", Applicable: true, Checked: true, Reason: "not set"},
+					{Source: "GITHUB_TOKEN", Applicable: true, Checked: true, Set: true, Chosen: true, Reason: "chosen"},
+					{Source: "oauth_token", Applicable: true, Checked: true, Set: true, Reason: "ignored because GITHUB_TOKEN takes precedence"},
+					{Source: "GH_TOKEN", Set: true, Reason: "only used for GitHub Enterprise Server hosts when the configuration file can not be read"},
+					{Source: "GITHUB_TOKEN", Set: true, Reason: "only used for GitHub Enterprise Server hosts when the configuration file can not be read"},
+				},
+			},
+		},
+		{
+			name:   "enterprise without any token",
+			host:   "other.com",
+			config: testHostsConfig(),
+			wantResolution: TokenResolution{
+				Host:   "other.com",
+				Source: "oauth_token",
+				Candidates: []TokenCandidate{
+					{Source: "GH_ENTERPRISE_TOKEN", Applicable: true, Checked: true, Reason: "not set"},
+					{Source: "GITHUB_ENTERPRISE_TOKEN", Applicable: true, Checked: true, Reason: "not set"},
+					{Source: "GITHUB_TOKEN", Reason: "only used for GitHub Enterprise Server hosts when running in Codespaces"},
+					{Source: "oauth_token", Applicable: true, Checked: true, Reason: "not set"},
+					{Source: "GH_TOKEN", Reason: "only used for GitHub Enterprise Server hosts when the configuration file can not be read"},
+					{Source: "GITHUB_TOKEN", Reason: "only used for GitHub Enterprise Server hosts when the configuration file can not be read"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "CODESPACES"} {
+				t.Setenv(k, tt.env[k])
+			}
+			token, res := resolveToken(tt.config, tt.host)
+			assert.Equal(t, tt.wantToken, token)
+			assert.Equal(t, tt.wantResolution, res)
+		})
+	}
+}
+
+func TestExplainTokenForHost(t *testing.T) {
+	for _, k := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "CODESPACES"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_PATH", "/nonexistent/gh")
+
+	res := ExplainTokenForHost("github.com")
+	assert.False(t, res.Found)
+	assert.Equal(t, TokenCandidate{
+		Source:     "gh",
+		Applicable: true,
+		Checked:    true,
+		Reason:     "no token found in the system keyring",
+	}, res.Candidates[len(res.Candidates)-1])
+
+	t.Setenv("GH_TOKEN", "gh_token")
+	res = ExplainTokenForHost("github.com")
+	assert.True(t, res.Found)
+	assert.Equal(t, "GH_TOKEN", res.Source)
+	assert.Equal(t, TokenCandidate{
+		Source:     "gh",
+		Applicable: true,
+		Reason:     "not checked because a token was found in GH_TOKEN",
+	}, res.Candidates[len(res.Candidates)-1])
+}
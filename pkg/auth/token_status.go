@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	githubActions        = "GITHUB_ACTIONS"
+	oauthScopesHeader    = "X-OAuth-Scopes"
+	tokenExpirationField = "GitHub-Authentication-Token-Expiration"
+
+	// integrationMessage is the message of the 403 response to tokens
+	// of GitHub App installations for endpoints they can not access.
+	integrationMessage = "Resource not accessible by integration"
+)
+
+// TokenType describes the kind of an authentication token.
+type TokenType string
+
+const (
+	TokenTypeUnknown         TokenType = "unknown"
+	TokenTypeClassic         TokenType = "classic"
+	TokenTypeFineGrained     TokenType = "fine-grained"
+	TokenTypeOAuth           TokenType = "oauth"
+	TokenTypeAppUser         TokenType = "app-user"
+	TokenTypeAppInstallation TokenType = "app-installation"
+	TokenTypeActions         TokenType = "actions"
+)
+
+// TokenStatus describes the result of inspecting an authentication token.
+type TokenStatus struct {
+	// Host is the host the token was inspected against.
+	Host string
+
+	// Source is the source of the token as returned by TokenForHost.
+	Source string
+
+	// Type is the kind of the token, determined from its prefix and source.
+	Type TokenType
+
+	// Valid reports whether the API accepted the token.
+	Valid bool
+
+	// Login is the login of the authenticated user. It is empty for tokens
+	// that do not belong to a user, such as GitHub App installation tokens.
+	Login string
+
+	// Scopes are the OAuth scopes granted to the token. Scopes are only
+	// reported for classic personal access tokens and OAuth tokens,
+	// as other tokens use fine-grained permissions instead.
+	Scopes []string
+
+	// ScopesReported is true if the API reported the scopes of the token.
+	ScopesReported bool
+
+	// ExpiresAt is the time the token expires. It is the zero time
+	// if the token does not expire or its expiration was not reported.
+	ExpiresAt time.Time
+}
+
+// MissingScopesError is returned by TokenStatus.RequireScopes when the token
+// has not been granted all of the required scopes.
+type MissingScopesError struct {
+	Host    string
+	Missing []string
+}
+
+// Allow MissingScopesError to satisfy error interface.
+func (e *MissingScopesError) Error() string {
+	return fmt.Sprintf("the token for %s is missing required scopes %s; to request them run: gh auth refresh -h %s -s %s",
+		e.Host, quoteScopes(e.Missing), e.Host, strings.Join(e.Missing, ","))
+}
+
+// impliedScopes lists scopes that are granted as part of a broader scope.
+var impliedScopes = map[string][]string{
+	"repo":                      {"repo:status", "repo_deployment", "public_repo", "repo:invite", "security_events"},
+	"admin:org":                 {"write:org", "read:org", "manage_runners:org"},
+	"write:org":                 {"read:org"},
+	"admin:public_key":          {"write:public_key", "read:public_key"},
+	"write:public_key":          {"read:public_key"},
+	"admin:repo_hook":           {"write:repo_hook", "read:repo_hook"},
+	"write:repo_hook":           {"read:repo_hook"},
+	"admin:gpg_key":             {"write:gpg_key", "read:gpg_key"},
+	"write:gpg_key":             {"read:gpg_key"},
+	"admin:ssh_signing_key":     {"write:ssh_signing_key", "read:ssh_signing_key"},
+	"write:ssh_signing_key":     {"read:ssh_signing_key"},
+	"write:packages":            {"read:packages"},
+	"user":                      {"read:user", "user:email", "user:follow"},
+	"project":                   {"read:project"},
+	"admin:enterprise":          {"manage_runners:enterprise", "manage_billing:enterprise", "read:enterprise"},
+	"manage_billing:enterprise": {"read:enterprise"},
+}
+
+// HasScope reports whether the token has been granted the scope,
+// either directly or as part of a broader scope.
+func (s *TokenStatus) HasScope(scope string) bool {
+	for _, granted := range s.Scopes {
+		if granted == scope || implies(granted, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func implies(granted, scope string) bool {
+	for _, implied := range impliedScopes[granted] {
+		if implied == scope || implies(implied, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScopes returns a *MissingScopesError naming any of the scopes that
+// have not been granted to the token. If the API did not report the scopes
+// of the token, as is the case for fine-grained tokens, nil is returned.
+func (s *TokenStatus) RequireScopes(scopes ...string) error {
+	if !s.ScopesReported {
+		return nil
+	}
+	var missing []string
+	for _, scope := range scopes {
+		if !s.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return &MissingScopesError{Host: s.Host, Missing: missing}
+}
+
+// CheckTokenOptions holds optional configuration for CheckTokenWithOptions.
+type CheckTokenOptions struct {
+	// Host is the host to check the token for.
+	// Default is the host determined from the config, see DefaultHost.
+	Host string
+
+	// HTTPClient is the client used to send the API request, such as a client
+	// created with api.NewHTTPClient to use the same transport as API clients.
+	// Default is http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// CheckToken wraps CheckTokenWithContext with context.Background.
+func CheckToken(host string) (*TokenStatus, error) {
+	return CheckTokenWithContext(context.Background(), host)
+}
+
+// CheckTokenWithContext wraps CheckTokenWithOptions for the specified host.
+func CheckTokenWithContext(ctx context.Context, host string) (*TokenStatus, error) {
+	return CheckTokenWithOptions(ctx, CheckTokenOptions{Host: host})
+}
+
+// CheckTokenWithOptions resolves the token for the host using TokenForHost,
+// and makes a single API request to determine its status.
+// An error is returned if no token is found, the API could not be reached, or the API
+// responded with an error that does not tell whether the token is valid, such as a rate
+// limit or SAML enforcement, whereas a token rejected by the API is reported by TokenStatus.Valid.
+func CheckTokenWithOptions(ctx context.Context, opts CheckTokenOptions) (*TokenStatus, error) {
+	host := opts.Host
+	if host == "" {
+		host, _ = DefaultHost()
+	}
+	token, source := TokenForHost(host)
+	if token == "" {
+		return nil, fmt.Errorf("authentication token not found for host %s", host)
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return checkToken(ctx, client, host, token, source)
+}
+
+func checkToken(ctx context.Context, client *http.Client, host, token, source string) (*TokenStatus, error) {
+	status := &TokenStatus{
+		Host:   host,
+		Source: source,
+		Type:   tokenType(token, source),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, NewHost(host).RESTURL()+"user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return status, nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	message := parseMessage(b)
+	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+	// Installation tokens can not access the endpoint, which does not
+	// mean they are invalid. Other errors do not tell about the token.
+	if !success && (resp.StatusCode != http.StatusForbidden || message != integrationMessage) {
+		if message != "" {
+			return nil, fmt.Errorf("HTTP %d: %s (%s)", resp.StatusCode, message, req.URL)
+		}
+		return nil, fmt.Errorf("HTTP %d (%s)", resp.StatusCode, req.URL)
+	}
+	status.Valid = true
+
+	if values, ok := resp.Header[http.CanonicalHeaderKey(oauthScopesHeader)]; ok {
+		status.ScopesReported = true
+		for _, v := range values {
+			for _, scope := range strings.Split(v, ",") {
+				if scope = strings.TrimSpace(scope); scope != "" {
+					status.Scopes = append(status.Scopes, scope)
+				}
+			}
+		}
+	}
+
+	if expiration := resp.Header.Get(tokenExpirationField); expiration != "" {
+		status.ExpiresAt = parseTokenExpiration(expiration)
+	}
+
+	if success {
+		status.Login = parseLogin(b)
+	}
+
+	return status, nil
+}
+
+func tokenType(token, source string) TokenType {
+	switch {
+	case strings.HasPrefix(token, "ghp_"):
+		return TokenTypeClassic
+	case strings.HasPrefix(token, "github_pat_"):
+		return TokenTypeFineGrained
+	case strings.HasPrefix(token, "gho_"):
+		return TokenTypeOAuth
+	case strings.HasPrefix(token, "ghu_"):
+		return TokenTypeAppUser
+	case strings.HasPrefix(token, "ghs_"):
+		if source == githubToken && os.Getenv(githubActions) == "true" {
+			return TokenTypeActions
+		}
+		return TokenTypeAppInstallation
+	default:
+		return TokenTypeUnknown
+	}
+}
+
+// parseTokenExpiration parses the expiration header, which is formatted
+// like "2023-06-05 14:41:50 UTC" or with a numeric zone offset.
+func parseTokenExpiration(s string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func parseMessage(b []byte) string {
+	var apiError struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(b, &apiError); err != nil {
+		return ""
+	}
+	return apiError.Message
+}
+
+func parseLogin(b []byte) string {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(b, &user); err != nil {
+		return ""
+	}
+	return user.Login
+}
+
+func quoteScopes(scopes []string) string {
+	quoted := make([]string, len(scopes))
+	for i, s := range scopes {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return strings.Join(quoted, ", ")
+}
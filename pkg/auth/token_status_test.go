@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		token      string
+		source     string
+		actions    bool
+		status     int
+		headers    http.Header
+		body       string
+		wantURL    string
+		wantStatus *TokenStatus
+		wantErr    string
+	}{
+		{
+			name:   "classic token",
+			host:   "github.com",
+			token:  "ghp_abc",
+			source: "oauth_token",
+			status: 200,
+			headers: http.Header{
+				"X-Oauth-Scopes":                         []string{"repo, read:org, gist"},
+				"Github-Authentication-Token-Expiration": []string{"2030-06-05 14:41:50 UTC"},
+			},
+			body:    `{"login": "monalisa"}`,
+			wantURL: "https://api.github.com/user",
+			wantStatus: &TokenStatus{
+				Host:           "github.com",
+				Source:         "oauth_token",
+				Type:           TokenTypeClassic,
+				Valid:          true,
+				Login:          "monalisa",
+				Scopes:         []string{"repo", "read:org", "gist"},
+				ScopesReported: true,
+				ExpiresAt:      time.Date(2030, 6, 5, 14, 41, 50, 0, time.UTC),
+			},
+		},
+		{
+			name:    "fine-grained token on enterprise",
+			host:    "ghe.io",
+			token:   "github_pat_abc",
+			source:  "GH_ENTERPRISE_TOKEN",
+			status:  200,
+			body:    `{"login": "monalisa"}`,
+			wantURL: "https://ghe.io/api/v3/user",
+			wantStatus: &TokenStatus{
+				Host:   "ghe.io",
+				Source: "GH_ENTERPRISE_TOKEN",
+				Type:   TokenTypeFineGrained,
+				Valid:  true,
+				Login:  "monalisa",
+			},
+		},
+		{
+			name:    "installation token on tenancy",
+			host:    "tenant.ghe.com",
+			token:   "ghs_abc",
+			source:  "GH_TOKEN",
+			status:  403,
+			body:    `{"message": "Resource not accessible by integration"}`,
+			wantURL: "https://api.tenant.ghe.com/user",
+			wantStatus: &TokenStatus{
+				Host:   "tenant.ghe.com",
+				Source: "GH_TOKEN",
+				Type:   TokenTypeAppInstallation,
+				Valid:  true,
+			},
+		},
+		{
+			name:    "actions token",
+			host:    "github.com",
+			token:   "ghs_abc",
+			source:  "GITHUB_TOKEN",
+			actions: true,
+			status:  403,
+			body:    `{"message": "Resource not accessible by integration"}`,
+			wantURL: "https://api.github.com/user",
+			wantStatus: &TokenStatus{
+				Host:   "github.com",
+				Source: "GITHUB_TOKEN",
+				Type:   TokenTypeActions,
+				Valid:  true,
+			},
+		},
+		{
+			name:    "revoked oauth token",
+			host:    "github.com",
+			token:   "gho_abc",
+			source:  "oauth_token",
+			status:  401,
+			body:    `{"message": "Bad credentials"}`,
+			wantURL: "https://api.github.com/user",
+			wantStatus: &TokenStatus{
+				Host:   "github.com",
+				Source: "oauth_token",
+				Type:   TokenTypeOAuth,
+			},
+		},
+		{
+			name:    "SAML enforcement",
+			host:    "github.com",
+			token:   "ghp_abc",
+			source:  "oauth_token",
+			status:  403,
+			body:    `{"message": "Resource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization."}`,
+			wantURL: "https://api.github.com/user",
+			wantErr: "HTTP 403: Resource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization. (https://api.github.com/user)",
+		},
+		{
+			name:    "secondary rate limit",
+			host:    "github.com",
+			token:   "ghp_abc",
+			source:  "oauth_token",
+			status:  403,
+			headers: http.Header{"Retry-After": []string{"60"}},
+			body:    `{"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`,
+			wantURL: "https://api.github.com/user",
+			wantErr: "HTTP 403: You have exceeded a secondary rate limit. Please wait a few minutes before you try again. (https://api.github.com/user)",
+		},
+		{
+			name:    "too many requests",
+			host:    "github.com",
+			token:   "ghp_abc",
+			source:  "oauth_token",
+			status:  429,
+			wantURL: "https://api.github.com/user",
+			wantErr: "HTTP 429 (https://api.github.com/user)",
+		},
+		{
+			name:    "not found",
+			host:    "ghe.io",
+			token:   "ghp_abc",
+			source:  "oauth_token",
+			status:  404,
+			body:    `{"message": "Not Found"}`,
+			wantURL: "https://ghe.io/api/v3/user",
+			wantErr: "HTTP 404: Not Found (https://ghe.io/api/v3/user)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.actions {
+				t.Setenv("GITHUB_ACTIONS", "true")
+			} else {
+				t.Setenv("GITHUB_ACTIONS", "")
+			}
+			client := &http.Client{Transport: tripper(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, tt.wantURL, req.URL.String())
+				assert.Equal(t, "token "+tt.token, req.Header.Get("Authorization"))
+				h := tt.headers
+				if h == nil {
+					h = http.Header{}
+				}
+				return &http.Response{
+					StatusCode: tt.status,
+					Header:     h,
+					Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+					Request:    req,
+				}, nil
+			})}
+			status, err := checkToken(context.Background(), client, tt.host, tt.token, tt.source)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, status)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestCheckTokenWithOptions(t *testing.T) {
+	for _, k := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "CODESPACES"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghp_enterprise")
+
+	client := &http.Client{Transport: tripper(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "https://ghe.io/api/v3/user", req.URL.String())
+		assert.Equal(t, "token ghp_enterprise", req.Header.Get("Authorization"))
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{},
+			Body:       io.NopCloser(bytes.NewBufferString(`{"login":"monalisa"}`)),
+			Request:    req,
+		}, nil
+	})}
+	status, err := CheckTokenWithOptions(context.Background(), CheckTokenOptions{Host: "ghe.io", HTTPClient: client})
+	assert.NoError(t, err)
+	assert.Equal(t, &TokenStatus{
+		Host:   "ghe.io",
+		Source: "GH_ENTERPRISE_TOKEN",
+		Type:   TokenTypeClassic,
+		Valid:  true,
+		Login:  "monalisa",
+	}, status)
+}
+
+func TestRequireScopes(t *testing.T) {
+	status := &TokenStatus{
+		Host:           "github.com",
+		Scopes:         []string{"repo", "admin:org"},
+		ScopesReported: true,
+	}
+	assert.NoError(t, status.RequireScopes("repo", "public_repo", "read:org"))
+	err := status.RequireScopes("workflow", "read:org", "gist")
+	assert.EqualError(t, err, `the token for github.com is missing required scopes "gist", "workflow"; to request them run: gh auth refresh -h github.com -s gist,workflow`)
+	var scopesErr *MissingScopesError
+	assert.ErrorAs(t, err, &scopesErr)
+	assert.Equal(t, []string{"gist", "workflow"}, scopesErr.Missing)
+
+	fineGrained := &TokenStatus{Host: "github.com"}
+	assert.NoError(t, fineGrained.RequireScopes("workflow"))
+}
+
+type tripper func(*http.Request) (*http.Response, error)
+
+func (tr tripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return tr(req)
+}
@@ -0,0 +1,127 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrBranchNotFound is returned by BranchInfo when the branch does not exist.
+var ErrBranchNotFound = errors.New("git: branch not found")
+
+// BranchInfo holds the configuration and upstream tracking state of a local branch.
+type BranchInfo struct {
+	// Name is the short name of the branch, such as "main".
+	Name string
+
+	// Remote is the value of branch.<name>.remote: the name of the remote
+	// the branch tracks, "." for a local branch, or empty if not configured.
+	Remote string
+
+	// Merge is the value of branch.<name>.merge: the ref on the remote
+	// the branch tracks, such as "refs/heads/main", or empty if not configured.
+	Merge string
+
+	// MergeBase is the value of branch.<name>.gh-merge-base: the branch pull requests
+	// for the branch are opened against, as set by gh, or empty if not configured.
+	MergeBase string
+
+	// UpstreamRef is the full name of the ref the branch tracks, such as
+	// "refs/remotes/origin/main", or empty if the branch has no upstream.
+	UpstreamRef string
+
+	// UpstreamGone is true if the branch has an upstream that does not
+	// exist, for example because the remote branch has been deleted.
+	UpstreamGone bool
+
+	// Ahead and Behind are the number of commits the branch is ahead and behind its
+	// upstream. They are zero if the branch has no upstream or the upstream is gone.
+	Ahead  int
+	Behind int
+}
+
+// BranchInfo returns the configuration and upstream tracking state of the specified
+// local branch, or of the current branch if branch is empty. If the branch does not
+// exist the error is ErrBranchNotFound.
+func (c *Client) BranchInfo(ctx context.Context, branch string) (BranchInfo, error) {
+	if branch == "" {
+		var err error
+		branch, err = c.CurrentBranch(ctx)
+		if err != nil {
+			return BranchInfo{}, err
+		}
+	}
+	info := BranchInfo{Name: branch}
+	ref := "refs/heads/" + branch
+
+	out, err := c.output(ctx, "for-each-ref", "--format=%(refname)%00%(upstream)%00%(upstream:track)", ref)
+	if err != nil {
+		return info, err
+	}
+	found := false
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) == 3 && fields[0] == ref {
+			found = true
+			info.UpstreamRef = fields[1]
+			info.UpstreamGone = fields[2] == "[gone]"
+			break
+		}
+	}
+	if !found {
+		return info, fmt.Errorf("%w: %s", ErrBranchNotFound, branch)
+	}
+
+	if err := c.readBranchConfig(ctx, &info); err != nil {
+		return info, err
+	}
+
+	if info.UpstreamRef == "" || info.UpstreamGone {
+		return info, nil
+	}
+	counts, err := c.output(ctx, "rev-list", "--left-right", "--count", ref+"..."+info.UpstreamRef)
+	if err != nil {
+		return info, err
+	}
+	fields := strings.Fields(counts)
+	if len(fields) != 2 {
+		return info, fmt.Errorf("git: unexpected rev-list output %q", counts)
+	}
+	if info.Ahead, err = strconv.Atoi(fields[0]); err != nil {
+		return info, fmt.Errorf("git: unexpected rev-list output %q", counts)
+	}
+	if info.Behind, err = strconv.Atoi(fields[1]); err != nil {
+		return info, fmt.Errorf("git: unexpected rev-list output %q", counts)
+	}
+	return info, nil
+}
+
+func (c *Client) readBranchConfig(ctx context.Context, info *BranchInfo) error {
+	prefix := "branch." + info.Name + "."
+	pattern := fmt.Sprintf("^%s(remote|merge|gh-merge-base)$", regexp.QuoteMeta(prefix))
+	out, err := c.output(ctx, "config", "--get-regexp", pattern)
+	if err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+			// None of the keys are set.
+			return nil
+		}
+		return err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		// Keys are reported with the section and variable name lowercased.
+		switch strings.ToLower(key[len(prefix):]) {
+		case "remote":
+			info.Remote = value
+		case "merge":
+			info.Merge = value
+		case "gh-merge-base":
+			info.MergeBase = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientBranchInfo(t *testing.T) {
+	ctx := context.Background()
+	c := newTestRepo(t)
+	run(t, c, "remote", "add", "origin", "https://github.com/monalisa/go-gh.git")
+
+	// origin/main has one commit that main does not have, and vice versa.
+	run(t, c, "checkout", "--quiet", "-b", "remote-main")
+	writeFile(t, c, "remote.txt", "remote\n")
+	run(t, c, "add", "remote.txt")
+	run(t, c, "commit", "--quiet", "-m", "remote commit")
+	run(t, c, "update-ref", "refs/remotes/origin/main", "remote-main")
+	run(t, c, "checkout", "--quiet", "main")
+	writeFile(t, c, "local.txt", "local\n")
+	run(t, c, "add", "local.txt")
+	run(t, c, "commit", "--quiet", "-m", "local commit")
+	run(t, c, "branch", "--quiet", "--set-upstream-to", "origin/main")
+	run(t, c, "config", "branch.main.gh-merge-base", "develop")
+
+	run(t, c, "branch", "feature.one")
+	run(t, c, "config", "branch.feature.one.remote", "origin")
+	run(t, c, "config", "branch.feature.one.merge", "refs/heads/deleted")
+
+	tests := []struct {
+		name     string
+		branch   string
+		wantInfo BranchInfo
+		wantErr  error
+	}{
+		{
+			name:   "current branch",
+			branch: "",
+			wantInfo: BranchInfo{
+				Name:        "main",
+				Remote:      "origin",
+				Merge:       "refs/heads/main",
+				MergeBase:   "develop",
+				UpstreamRef: "refs/remotes/origin/main",
+				Ahead:       1,
+				Behind:      1,
+			},
+		},
+		{
+			name:   "upstream gone",
+			branch: "feature.one",
+			wantInfo: BranchInfo{
+				Name:         "feature.one",
+				Remote:       "origin",
+				Merge:        "refs/heads/deleted",
+				UpstreamRef:  "refs/remotes/origin/deleted",
+				UpstreamGone: true,
+			},
+		},
+		{
+			name:     "no upstream",
+			branch:   "remote-main",
+			wantInfo: BranchInfo{Name: "remote-main"},
+		},
+		{
+			name:    "branch not found",
+			branch:  "feature",
+			wantErr: ErrBranchNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := c.BranchInfo(ctx, tt.branch)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantInfo, info)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package git is a set of types and functions for running git commands
+// and querying git repositories.
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	internalgit "github.com/cli/go-gh/v2/internal/git"
+)
+
+// Client runs git commands. The zero value is ready to use and runs
+// the git executable found in PATH in the current directory.
+type Client struct {
+	// GitPath is the path to the git executable.
+	// Default is to look up git in PATH.
+	GitPath string
+
+	// Dir is the directory git is run in.
+	// Default is the current directory of the process.
+	Dir string
+
+	// Env holds additional environment variables of the form "KEY=value"
+	// that are appended to the environment of the process.
+	Env []string
+}
+
+// StatusEntry is a changed path reported by Status.
+type StatusEntry struct {
+	// Index and WorkTree are the status codes of the path in the index and the
+	// working tree, such as 'M' for modified or '?' for untracked, as described
+	// in the short format section of git-status(1).
+	Index    byte
+	WorkTree byte
+
+	// Path is the path of the file relative to the repository root.
+	Path string
+
+	// OrigPath is the path the file was renamed or copied from, if any.
+	OrigPath string
+}
+
+// Command returns an *exec.Cmd to run git with the specified arguments,
+// in the directory and environment of the client.
+func (c *Client) Command(ctx context.Context, args ...string) (*exec.Cmd, error) {
+	return internalgit.Command(ctx, c.GitPath, c.Dir, c.Env, args...)
+}
+
+// Run runs git with the specified arguments and returns its standard output.
+// If git can not be run or exits with a non-zero status the error is a *GitError.
+func (c *Client) Run(ctx context.Context, args ...string) ([]byte, error) {
+	return c.run(ctx, nil, nil, args...)
+}
+
+// run runs git with the additional environment variables env, and
+// copies its standard error output to stderr if it is not nil.
+func (c *Client) run(ctx context.Context, env []string, stderr io.Writer, args ...string) ([]byte, error) {
+	cmd, err := internalgit.Command(ctx, c.GitPath, c.Dir, append(c.Env[:len(c.Env):len(c.Env)], env...), args...)
+	if err != nil {
+		return nil, err
+	}
+	stdOut, _, err := internalgit.Run(cmd, stderr)
+	return stdOut.Bytes(), err
+}
+
+// output runs git and returns its standard output without the trailing newline.
+func (c *Client) output(ctx context.Context, args ...string) (string, error) {
+	out, err := c.Run(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// CurrentBranch returns the name of the checked out branch.
+// If HEAD is detached the error is ErrNotOnAnyBranch.
+func (c *Client) CurrentBranch(ctx context.Context) (string, error) {
+	branch, err := c.output(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+			return "", ErrNotOnAnyBranch
+		}
+		return "", err
+	}
+	return branch, nil
+}
+
+// HeadSHA returns the SHA of the commit HEAD points to.
+func (c *Client) HeadSHA(ctx context.Context) (string, error) {
+	return c.output(ctx, "rev-parse", "HEAD")
+}
+
+// DefaultBranch returns the default branch of the specified remote as recorded
+// by the refs/remotes/REMOTE/HEAD reference, which is set when cloning.
+func (c *Client) DefaultBranch(ctx context.Context, remote string) (string, error) {
+	ref, err := c.output(ctx, "symbolic-ref", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", remote))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(ref, remote+"/"), nil
+}
+
+// Status returns the paths that differ between HEAD, the index, and the working
+// tree, including untracked files.
+func (c *Client) Status(ctx context.Context) ([]StatusEntry, error) {
+	out, err := c.Run(ctx, "status", "--porcelain", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(string(out)), nil
+}
+
+func parseStatus(out string) []StatusEntry {
+	var entries []StatusEntry
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if len(f) < 4 {
+			continue
+		}
+		e := StatusEntry{Index: f[0], WorkTree: f[1], Path: f[3:]}
+		// The original path of renames and copies follows as a separate field.
+		if (e.Index == 'R' || e.Index == 'C') && i+1 < len(fields) {
+			i++
+			e.OrigPath = fields[i]
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// Config returns the value of the specified configuration key.
+// If the key is not set the error is ErrConfigNotFound.
+func (c *Client) Config(ctx context.Context, key string) (string, error) {
+	value, err := c.output(ctx, "config", "--get", key)
+	if err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+			return "", ErrConfigNotFound
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+// SetConfig sets the value of the specified configuration key in the repository configuration.
+func (c *Client) SetConfig(ctx context.Context, key, value string) error {
+	_, err := c.Run(ctx, "config", key, value)
+	return err
+}
+
+// RevParse runs git rev-parse with the specified arguments and returns its output lines.
+func (c *Client) RevParse(ctx context.Context, args ...string) ([]string, error) {
+	out, err := c.output(ctx, append([]string{"rev-parse"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// MergeBase returns the best common ancestor of the specified commits.
+func (c *Client) MergeBase(ctx context.Context, a, b string) (string, error) {
+	return c.output(ctx, "merge-base", a, b)
+}
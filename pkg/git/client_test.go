@@ -0,0 +1,187 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepo initializes a repository with a single commit on the main branch
+// in a temporary directory and returns a client running in it.
+func newTestRepo(t *testing.T) *Client {
+	t.Helper()
+	c := &Client{
+		Dir: t.TempDir(),
+		Env: []string{
+			"GIT_CONFIG_GLOBAL=" + os.DevNull,
+			"GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=Mona Lisa",
+			"GIT_AUTHOR_EMAIL=monalisa@github.com",
+			"GIT_COMMITTER_NAME=Mona Lisa",
+			"GIT_COMMITTER_EMAIL=monalisa@github.com",
+		},
+	}
+	run(t, c, "init", "--quiet", "-b", "main")
+	writeFile(t, c, "README.md", "hello\n")
+	run(t, c, "add", "README.md")
+	run(t, c, "commit", "--quiet", "-m", "initial commit")
+	return c
+}
+
+func run(t *testing.T, c *Client, args ...string) string {
+	t.Helper()
+	out, err := c.Run(context.Background(), args...)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func writeFile(t *testing.T, c *Client, name, content string) {
+	t.Helper()
+	path := filepath.Join(c.Dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestClientRunError(t *testing.T) {
+	c := &Client{Dir: t.TempDir(), Env: []string{"GIT_CEILING_DIRECTORIES=" + os.TempDir()}}
+	_, err := c.Run(context.Background(), "rev-parse", "HEAD")
+	var gitErr *GitError
+	require.ErrorAs(t, err, &gitErr)
+	assert.Equal(t, 128, gitErr.ExitCode)
+	assert.Equal(t, []string{"rev-parse", "HEAD"}, gitErr.Args)
+	assert.Contains(t, gitErr.Stderr, "not a git repository")
+	assert.Contains(t, err.Error(), "failed to run git: fatal: not a git repository")
+
+	c = &Client{GitPath: filepath.Join(t.TempDir(), "nonexistent")}
+	_, err = c.Run(context.Background(), "status")
+	require.ErrorAs(t, err, &gitErr)
+	assert.Equal(t, -1, gitErr.ExitCode)
+}
+
+func TestClientQueries(t *testing.T) {
+	ctx := context.Background()
+	c := newTestRepo(t)
+
+	branch, err := c.CurrentBranch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "main", branch)
+
+	sha, err := c.HeadSHA(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, sha, 40)
+
+	revs, err := c.RevParse(ctx, "--abbrev-ref", "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main"}, revs)
+
+	run(t, c, "checkout", "--quiet", "-b", "feature")
+	writeFile(t, c, "feature.txt", "feature\n")
+	run(t, c, "add", "feature.txt")
+	run(t, c, "commit", "--quiet", "-m", "add feature")
+	base, err := c.MergeBase(ctx, "main", "feature")
+	assert.NoError(t, err)
+	assert.Equal(t, sha, base)
+
+	run(t, c, "checkout", "--quiet", "--detach")
+	_, err = c.CurrentBranch(ctx)
+	assert.ErrorIs(t, err, ErrNotOnAnyBranch)
+}
+
+func TestClientDefaultBranch(t *testing.T) {
+	c := newTestRepo(t)
+	run(t, c, "update-ref", "refs/remotes/origin/trunk", "HEAD")
+	run(t, c, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/trunk")
+
+	branch, err := c.DefaultBranch(context.Background(), "origin")
+	assert.NoError(t, err)
+	assert.Equal(t, "trunk", branch)
+
+	_, err = c.DefaultBranch(context.Background(), "upstream")
+	var gitErr *GitError
+	assert.ErrorAs(t, err, &gitErr)
+}
+
+func TestClientStatus(t *testing.T) {
+	c := newTestRepo(t)
+	writeFile(t, c, "README.md", "changed\n")
+	writeFile(t, c, "new file.txt", "new\n")
+	writeFile(t, c, "docs/guide.md", "guide\n")
+	run(t, c, "add", "docs/guide.md")
+	run(t, c, "mv", "README.md", "README")
+
+	entries, err := c.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []StatusEntry{
+		{Index: 'R', WorkTree: 'M', Path: "README", OrigPath: "README.md"},
+		{Index: 'A', WorkTree: ' ', Path: "docs/guide.md"},
+		{Index: '?', WorkTree: '?', Path: "new file.txt"},
+	}, entries)
+}
+
+func TestClientConfig(t *testing.T) {
+	ctx := context.Background()
+	c := newTestRepo(t)
+
+	_, err := c.Config(ctx, "remote.origin.gh-resolved")
+	assert.ErrorIs(t, err, ErrConfigNotFound)
+
+	assert.NoError(t, c.SetConfig(ctx, "remote.origin.gh-resolved", "base"))
+	value, err := c.Config(ctx, "remote.origin.gh-resolved")
+	assert.NoError(t, err)
+	assert.Equal(t, "base", value)
+}
+
+func TestClientRepoInfo(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	mainDir, err := filepath.EvalSymlinks(repo.Dir)
+	require.NoError(t, err)
+	mainGitDir := filepath.Join(mainDir, ".git")
+
+	writeFile(t, repo, "sub/dir/file.txt", "file\n")
+	info, err := (&Client{Dir: filepath.Join(repo.Dir, "sub", "dir"), Env: repo.Env}).RepoInfo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, RepoInfo{TopLevel: mainDir, GitDir: mainGitDir, CommonDir: mainGitDir}, info)
+
+	worktreeDir := filepath.Join(t.TempDir(), "worktree")
+	run(t, repo, "worktree", "add", "--quiet", worktreeDir)
+	worktreeDir, err = filepath.EvalSymlinks(worktreeDir)
+	require.NoError(t, err)
+	info, err = (&Client{Dir: worktreeDir, Env: repo.Env}).RepoInfo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, RepoInfo{
+		TopLevel:   worktreeDir,
+		GitDir:     filepath.Join(mainGitDir, "worktrees", "worktree"),
+		CommonDir:  mainGitDir,
+		IsWorktree: true,
+	}, info)
+
+	bareDir := filepath.Join(t.TempDir(), "bare.git")
+	run(t, repo, "clone", "--quiet", "--bare", mainDir, bareDir)
+	bareDir, err = filepath.EvalSymlinks(bareDir)
+	require.NoError(t, err)
+	info, err = (&Client{Dir: bareDir, Env: repo.Env}).RepoInfo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, RepoInfo{GitDir: bareDir, CommonDir: bareDir, IsBare: true}, info)
+
+	super := newTestRepo(t)
+	superDir, err := filepath.EvalSymlinks(super.Dir)
+	require.NoError(t, err)
+	run(t, super, "-c", "protocol.file.allow=always", "submodule", "--quiet", "add", mainDir, "module")
+	info, err = (&Client{Dir: filepath.Join(super.Dir, "module"), Env: super.Env}).RepoInfo(ctx)
+	assert.NoError(t, err)
+	superModuleDir := filepath.Join(superDir, ".git", "modules", "module")
+	assert.Equal(t, RepoInfo{
+		TopLevel:     filepath.Join(superDir, "module"),
+		GitDir:       superModuleDir,
+		CommonDir:    superModuleDir,
+		SuperProject: superDir,
+	}, info)
+
+	_, err = (&Client{Dir: t.TempDir(), Env: []string{"GIT_CEILING_DIRECTORIES=" + os.TempDir()}}).RepoInfo(ctx)
+	assert.ErrorIs(t, err, ErrNotRepository)
+}
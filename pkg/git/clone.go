@@ -0,0 +1,238 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	internalgit "github.com/cli/go-gh/v2/internal/git"
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+const (
+	protocolHTTPS = "https"
+	protocolSSH   = "ssh"
+
+	// credentialTokenEnv is the environment variable the ephemeral credential
+	// helper reads the token from, so that it does not appear in the process list.
+	credentialTokenEnv = "GO_GH_CREDENTIAL_TOKEN"
+)
+
+// CloneOptions holds optional configuration for Clone.
+type CloneOptions struct {
+	// Protocol is the protocol to clone with, either "https" or "ssh". Default is the
+	// git_protocol value of the host from the gh configuration file, or "https".
+	Protocol string
+
+	// Branch is the branch to check out instead of the default branch of the repository.
+	Branch string
+
+	// Depth creates a shallow clone with the specified number of commits if it is greater than zero.
+	Depth int
+
+	// Filter creates a partial clone with the specified object filter, such as "blob:none".
+	Filter string
+
+	// SparsePaths enables a sparse checkout in cone mode of the specified directories.
+	SparsePaths []string
+
+	// Upstream is added as the "upstream" remote and marked as the base repository of
+	// the clone for gh, which is useful when cloning a fork. It is cloned with the
+	// same protocol as the repository.
+	Upstream *repository.Repository
+
+	// Progress receives the progress output of git. Default is to discard it.
+	Progress io.Writer
+
+	// CredentialHelper is a command run by the shell as the credential helper for the
+	// host, with the operation appended as an argument, such as `"/path/to/gh-ext"
+	// git-credential` for a program that calls RunCredentialHelper. The command resolves
+	// the token itself, so it is not placed in the environment of git. Default is to
+	// pass the token of the host to git in the environment, see Clone.
+	CredentialHelper string
+
+	// Args are additional arguments for git clone.
+	Args []string
+}
+
+// FetchOptions holds optional configuration for Fetch.
+type FetchOptions struct {
+	// Depth limits fetching to the specified number of commits if it is greater than zero.
+	Depth int
+
+	// Progress receives the progress output of git. Default is to discard it.
+	Progress io.Writer
+
+	// CredentialHelper is a command run by the shell as the credential helper for the
+	// host, with the operation appended as an argument, such as `"/path/to/gh-ext"
+	// git-credential` for a program that calls RunCredentialHelper. The command resolves
+	// the token itself, so it is not placed in the environment of git. Default is to
+	// pass the token of the host to git in the environment, see Clone.
+	CredentialHelper string
+
+	// Args are additional arguments for git fetch.
+	Args []string
+}
+
+// CloneURL returns the URL to clone repo with the specified protocol.
+// An empty protocol uses the git_protocol value of the gh configuration file.
+func CloneURL(repo repository.Repository, protocol string) string {
+	if protocol == "" {
+		protocol = gitProtocol(repo.Host)
+	}
+	host := auth.NewHost(repo.Host)
+	if protocol == protocolSSH {
+		return fmt.Sprintf("git@%s:%s/%s.git", host.SSHHost(), repo.Owner, repo.Name)
+	}
+	return fmt.Sprintf("%s%s/%s.git", host.WebURL(), repo.Owner, repo.Name)
+}
+
+// Clone clones repo into dir, relative to the directory of the client, and returns
+// the path of the clone, which is relative to the current directory of the process
+// if the directory of the client is. If dir is empty the name of the repository is used.
+//
+// For HTTPS the token of the host is resolved with auth.TokenForHost and passed to git
+// by an ephemeral credential helper for the duration of the command, so that it is not
+// persisted in the configuration of the clone. Configured credential helpers for the
+// host are used if no token is found.
+//
+// The ephemeral credential helper reads the token from the GO_GH_CREDENTIAL_TOKEN
+// environment variable, which is inherited by every process git starts, including
+// hooks, submodule operations, and ssh or proxy commands. Set the CredentialHelper option
+// to a command that resolves the token itself to keep it out of the environment.
+func (c *Client) Clone(ctx context.Context, repo repository.Repository, dir string, opts CloneOptions) (string, error) {
+	protocol := opts.Protocol
+	if protocol == "" {
+		protocol = gitProtocol(repo.Host)
+	}
+	if dir == "" {
+		dir = repo.Name
+	}
+	// git clone runs in the directory of the client, so dir is passed as it is and
+	// only joined with that directory for the path of the clone.
+	path := dir
+	if !filepath.IsAbs(dir) && c.Dir != "" {
+		path = filepath.Join(c.Dir, dir)
+	}
+
+	cloneURL := CloneURL(repo, protocol)
+	authArgs, env := credentialArgs(cloneURL, opts.CredentialHelper)
+	args := append(authArgs, "clone")
+	args = append(args, progressArgs(opts.Progress)...)
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Filter != "" {
+		args = append(args, "--filter", opts.Filter)
+	}
+	if len(opts.SparsePaths) > 0 {
+		args = append(args, "--sparse")
+	}
+	args = append(args, opts.Args...)
+	args = append(args, "--", cloneURL, dir)
+	if _, err := c.run(ctx, env, opts.Progress, args...); err != nil {
+		return "", err
+	}
+
+	clone := &Client{GitPath: c.GitPath, Dir: path, Env: c.Env}
+	if len(opts.SparsePaths) > 0 {
+		args := append([]string{"sparse-checkout", "set", "--cone", "--"}, opts.SparsePaths...)
+		if _, err := clone.run(ctx, env, opts.Progress, append(authArgs, args...)...); err != nil {
+			return path, err
+		}
+	}
+
+	if opts.Upstream != nil {
+		upstreamURL := CloneURL(*opts.Upstream, protocol)
+		if _, err := clone.Run(ctx, "remote", "add", "upstream", upstreamURL); err != nil {
+			return path, err
+		}
+		if err := clone.SetConfig(ctx, "remote.upstream.gh-resolved", "base"); err != nil {
+			return path, err
+		}
+		fetchOpts := FetchOptions{Progress: opts.Progress, CredentialHelper: opts.CredentialHelper}
+		if opts.Filter != "" {
+			fetchOpts.Args = []string{"--filter", opts.Filter}
+		}
+		if err := clone.Fetch(ctx, "upstream", nil, fetchOpts); err != nil {
+			return path, err
+		}
+	}
+
+	return path, nil
+}
+
+// Fetch fetches the specified refspecs from remote, or the refspecs configured for
+// the remote if none are specified. Authentication for HTTPS remotes is handled as
+// described for Clone.
+func (c *Client) Fetch(ctx context.Context, remote string, refspecs []string, opts FetchOptions) error {
+	remoteURL, err := c.output(ctx, "remote", "get-url", remote)
+	if err != nil {
+		return err
+	}
+
+	authArgs, env := credentialArgs(remoteURL, opts.CredentialHelper)
+	args := append(authArgs, "fetch")
+	args = append(args, progressArgs(opts.Progress)...)
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	args = append(args, opts.Args...)
+	args = append(args, "--", remote)
+	args = append(args, refspecs...)
+	_, err = c.run(ctx, env, opts.Progress, args...)
+	return err
+}
+
+// credentialArgs returns the git arguments and environment variables that authenticate
+// HTTP(S) requests to the host of rawURL with the specified credential helper command,
+// or with the token of the host if no command is specified and a token is found.
+func credentialArgs(rawURL, command string) ([]string, []string) {
+	u, err := internalgit.ParseURL(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return nil, nil
+	}
+	// Credential helpers are scoped to the host so that the token is never
+	// sent to other hosts, for example those of submodules.
+	key := fmt.Sprintf("credential.%s://%s.helper", u.Scheme, u.Host)
+	if command != "" {
+		return []string{"-c", key + "=", "-c", key + "=!" + command}, nil
+	}
+	token, _ := auth.TokenForHost(u.Hostname())
+	if token == "" {
+		return nil, nil
+	}
+	helper := fmt.Sprintf(`!f() { test "$1" = get && echo username=%s && echo "password=$%s"; }; f`, tokenUser, credentialTokenEnv)
+	return []string{"-c", key + "=", "-c", key + "=" + helper}, []string{credentialTokenEnv + "=" + token}
+}
+
+func progressArgs(progress io.Writer) []string {
+	if progress == nil {
+		return []string{"--quiet"}
+	}
+	return []string{"--progress"}
+}
+
+// gitProtocol returns the git_protocol value of host from the gh configuration file,
+// falling back to the global value and then to "https".
+func gitProtocol(host string) string {
+	cfg, err := config.Read(nil)
+	if err != nil {
+		return protocolHTTPS
+	}
+	if protocol, err := cfg.Get([]string{"hosts", auth.NormalizeHostname(host), "git_protocol"}); err == nil && protocol != "" {
+		return strings.ToLower(protocol)
+	}
+	if protocol, err := cfg.Get([]string{"git_protocol"}); err == nil && protocol != "" {
+		return strings.ToLower(protocol)
+	}
+	return protocolHTTPS
+}
@@ -0,0 +1,196 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneURL(t *testing.T) {
+	stubConfig(t, `
+hosts:
+  enterprise.com:
+    git_protocol: ssh
+`)
+
+	tests := []struct {
+		name     string
+		repo     repository.Repository
+		protocol string
+		wantURL  string
+	}{
+		{
+			name:    "default protocol",
+			repo:    repository.Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"},
+			wantURL: "https://github.com/OWNER/REPO.git",
+		},
+		{
+			name:    "protocol from config",
+			repo:    repository.Repository{Host: "enterprise.com", Owner: "OWNER", Name: "REPO"},
+			wantURL: "git@enterprise.com:OWNER/REPO.git",
+		},
+		{
+			name:     "explicit protocol",
+			repo:     repository.Repository{Host: "enterprise.com", Owner: "OWNER", Name: "REPO"},
+			protocol: "https",
+			wantURL:  "https://enterprise.com/OWNER/REPO.git",
+		},
+		{
+			name:     "tenancy over ssh",
+			repo:     repository.Repository{Host: "api.tenant.ghe.com", Owner: "OWNER", Name: "REPO"},
+			protocol: "ssh",
+			wantURL:  "git@tenant.ghe.com:OWNER/REPO.git",
+		},
+		{
+			name:    "localhost",
+			repo:    repository.Repository{Host: "github.localhost", Owner: "OWNER", Name: "REPO"},
+			wantURL: "http://github.localhost/OWNER/REPO.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantURL, CloneURL(tt.repo, tt.protocol))
+		})
+	}
+}
+
+func TestCredentialArgs(t *testing.T) {
+	stubConfig(t, "")
+	t.Setenv("GH_TOKEN", "dotcom_token")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_ENTERPRISE_TOKEN", "")
+	t.Setenv("GH_PATH", filepath.Join(t.TempDir(), "nonexistent"))
+
+	args, env := credentialArgs("https://github.com/OWNER/REPO.git", "")
+	assert.Equal(t, []string{
+		"-c", "credential.https://github.com.helper=",
+		"-c", `credential.https://github.com.helper=!f() { test "$1" = get && echo username=x-access-token && echo "password=$GO_GH_CREDENTIAL_TOKEN"; }; f`,
+	}, args)
+	assert.Equal(t, []string{"GO_GH_CREDENTIAL_TOKEN=dotcom_token"}, env)
+
+	args, env = credentialArgs("git@github.com:OWNER/REPO.git", "")
+	assert.Nil(t, args)
+	assert.Nil(t, env)
+
+	args, env = credentialArgs("https://enterprise.com/OWNER/REPO.git", "")
+	assert.Nil(t, args)
+	assert.Nil(t, env)
+
+	args, env = credentialArgs("https://github.com/OWNER/REPO.git", `"/path/to/gh-ext" git-credential`)
+	assert.Equal(t, []string{
+		"-c", "credential.https://github.com.helper=",
+		"-c", `credential.https://github.com.helper=!"/path/to/gh-ext" git-credential`,
+	}, args)
+	assert.Nil(t, env)
+}
+
+func TestClientClone(t *testing.T) {
+	ctx := context.Background()
+	stubConfig(t, "")
+	for _, k := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("GH_PATH", filepath.Join(t.TempDir(), "nonexistent"))
+
+	// Serve https://github.com/ from bare repositories on disk.
+	remotesDir := t.TempDir()
+	source := newTestRepo(t)
+	writeFile(t, source, "docs/guide.md", "guide\n")
+	writeFile(t, source, "src/main.go", "package main\n")
+	run(t, source, "add", ".")
+	run(t, source, "commit", "--quiet", "-m", "add files")
+	run(t, source, "clone", "--quiet", "--bare", source.Dir, filepath.Join(remotesDir, "monalisa", "REPO.git"))
+	run(t, source, "clone", "--quiet", "--bare", source.Dir, filepath.Join(remotesDir, "OWNER", "REPO.git"))
+
+	c := &Client{
+		Dir: t.TempDir(),
+		Env: append(source.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=url.file://"+filepath.ToSlash(remotesDir)+"/.insteadOf",
+			"GIT_CONFIG_VALUE_0=https://github.com/",
+		),
+	}
+	progress := &bytes.Buffer{}
+	dir, err := c.Clone(ctx, repository.Repository{Host: "github.com", Owner: "monalisa", Name: "REPO"}, "", CloneOptions{
+		Depth:       1,
+		SparsePaths: []string{"docs"},
+		Upstream:    &repository.Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"},
+		Progress:    progress,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(c.Dir, "REPO"), dir)
+	assert.Contains(t, progress.String(), "Cloning into")
+
+	clone := &Client{Dir: dir, Env: c.Env}
+	origin, err := clone.Config(ctx, "remote.origin.url")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/monalisa/REPO.git", origin)
+	upstream, err := clone.Config(ctx, "remote.upstream.url")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/OWNER/REPO.git", upstream)
+	resolved, err := clone.Config(ctx, "remote.upstream.gh-resolved")
+	assert.NoError(t, err)
+	assert.Equal(t, "base", resolved)
+	_, err = clone.RevParse(ctx, "--verify", "refs/remotes/upstream/main")
+	assert.NoError(t, err)
+
+	shallow, err := clone.RevParse(ctx, "--is-shallow-repository")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"true"}, shallow)
+	assert.FileExists(t, filepath.Join(dir, "README.md"))
+	assert.FileExists(t, filepath.Join(dir, "docs", "guide.md"))
+	assert.NoFileExists(t, filepath.Join(dir, "src", "main.go"))
+
+	assert.NoError(t, clone.Fetch(ctx, "origin", []string{"main"}, FetchOptions{Depth: 1}))
+	err = clone.Fetch(ctx, "nonexistent", nil, FetchOptions{})
+	var gitErr *GitError
+	assert.ErrorAs(t, err, &gitErr)
+	assert.True(t, strings.Contains(gitErr.Stderr, "nonexistent"))
+}
+
+func TestClientClone_relativeDir(t *testing.T) {
+	ctx := context.Background()
+	stubConfig(t, "")
+	for _, k := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("GH_PATH", filepath.Join(t.TempDir(), "nonexistent"))
+
+	remotesDir := t.TempDir()
+	source := newTestRepo(t)
+	run(t, source, "clone", "--quiet", "--bare", source.Dir, filepath.Join(remotesDir, "OWNER", "REPO.git"))
+
+	workDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(workDir))
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+	require.NoError(t, os.Mkdir("work", 0o755))
+
+	c := &Client{
+		Dir: "work",
+		Env: append(source.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=url.file://"+filepath.ToSlash(remotesDir)+"/.insteadOf",
+			"GIT_CONFIG_VALUE_0=https://github.com/",
+		),
+	}
+	dir, err := c.Clone(ctx, repository.Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"}, "", CloneOptions{
+		Upstream: &repository.Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("work", "REPO"), dir)
+	assert.FileExists(t, filepath.Join(workDir, "work", "REPO", "README.md"))
+	assert.NoDirExists(t, filepath.Join(workDir, "work", "work"))
+
+	upstream, err := (&Client{Dir: dir, Env: c.Env}).Config(ctx, "remote.upstream.url")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/OWNER/REPO.git", upstream)
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// tokenUser is the username git uses with tokens that are not associated with an account.
+const tokenUser = "x-access-token"
+
+// Credential is a set of attributes exchanged with git in the credential helper protocol,
+// as described in git-credential(1).
+type Credential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// ReadCredential reads the "key=value" lines of a credential from r until a
+// blank line or the end of the input. Unknown attributes are ignored.
+func ReadCredential(r io.Reader) (Credential, error) {
+	var c Credential
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return c, fmt.Errorf("git: malformed credential attribute %q", line)
+		}
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		}
+	}
+	return c, scanner.Err()
+}
+
+// Write writes the non-empty attributes of the credential to w as "key=value" lines.
+func (c Credential) Write(w io.Writer) error {
+	for _, attr := range [][2]string{
+		{"protocol", c.Protocol},
+		{"host", c.Host},
+		{"path", c.Path},
+		{"username", c.Username},
+		{"password", c.Password},
+	} {
+		if attr[1] == "" {
+			continue
+		}
+		if strings.ContainsAny(attr[1], "\n\x00") {
+			return fmt.Errorf("git: credential attribute %s contains a newline or NUL byte", attr[0])
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", attr[0], attr[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunCredentialHelper implements the git credential helper protocol for the specified
+// operation, reading the request from in and writing the response to out. It allows
+// a program to be configured as a git credential helper, see CredentialHelperArgs.
+//
+// The "get" operation resolves the token of HTTPS hosts with auth.TokenForHost. If git
+// asks for a specific username that is not the active account, the token of that account
+// is resolved with auth.TokenForUser. Nothing is written if no token is found, so that git
+// falls back to other helpers or prompts. The "store" and "erase" operations are ignored,
+// as tokens are managed by gh.
+func RunCredentialHelper(operation string, in io.Reader, out io.Writer) error {
+	switch operation {
+	case "get":
+	case "store", "erase":
+		return nil
+	default:
+		return fmt.Errorf("git: unsupported credential operation %q", operation)
+	}
+
+	wants, err := ReadCredential(in)
+	if err != nil {
+		return err
+	}
+	if wants.Protocol != "https" || wants.Host == "" {
+		return nil
+	}
+
+	token, source := auth.TokenForHost(wants.Host)
+	username, _ := auth.ActiveUser(wants.Host)
+	if strings.HasSuffix(source, "_TOKEN") || username == "" {
+		// Tokens from environment variables are not associated with an account.
+		username = tokenUser
+	}
+	if wants.Username != "" && wants.Username != username && wants.Username != tokenUser {
+		username = wants.Username
+		token, _ = auth.TokenForUser(wants.Host, wants.Username)
+	}
+	if token == "" {
+		return nil
+	}
+
+	return Credential{
+		Protocol: wants.Protocol,
+		Host:     wants.Host,
+		Username: username,
+		Password: token,
+	}.Write(out)
+}
+
+// CredentialHelperArgs returns git arguments that replace all configured credential
+// helpers with the specified command for a single git invocation, for example
+// append(CredentialHelperArgs(`"/path/to/gh-ext" git-credential`), "fetch").
+// The command is run by the shell with the operation appended as an argument,
+// and would typically call RunCredentialHelper.
+func CredentialHelperArgs(command string) []string {
+	return []string{
+		"-c", "credential.helper=",
+		"-c", "credential.helper=!" + command,
+	}
+}
@@ -0,0 +1,139 @@
+package git
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCredentialHelper(t *testing.T) {
+	for _, k := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "CODESPACES"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("GH_PATH", filepath.Join(t.TempDir(), "nonexistent"))
+	stubConfig(t, `
+hosts:
+  github.com:
+    user: monalisa
+    oauth_token: monalisa_token
+    users:
+      monalisa:
+        oauth_token: monalisa_token
+      hubot:
+        oauth_token: hubot_token
+`)
+
+	tests := []struct {
+		name      string
+		operation string
+		input     string
+		env       map[string]string
+		wantOut   string
+		wantErr   string
+	}{
+		{
+			name:      "active account",
+			operation: "get",
+			input:     "protocol=https\nhost=github.com\npath=cli/go-gh.git\n\n",
+			wantOut:   "protocol=https\nhost=github.com\nusername=monalisa\npassword=monalisa_token\n",
+		},
+		{
+			name:      "token username",
+			operation: "get",
+			input:     "protocol=https\nhost=github.com\nusername=x-access-token\n",
+			wantOut:   "protocol=https\nhost=github.com\nusername=monalisa\npassword=monalisa_token\n",
+		},
+		{
+			name:      "other account",
+			operation: "get",
+			input:     "protocol=https\nhost=github.com\nusername=hubot\n",
+			wantOut:   "protocol=https\nhost=github.com\nusername=hubot\npassword=hubot_token\n",
+		},
+		{
+			name:      "unknown account",
+			operation: "get",
+			input:     "protocol=https\nhost=github.com\nusername=octocat\n",
+			wantOut:   "",
+		},
+		{
+			name:      "environment variable",
+			operation: "get",
+			input:     "protocol=https\nhost=github.com\n",
+			env:       map[string]string{"GH_TOKEN": "env_token"},
+			wantOut:   "protocol=https\nhost=github.com\nusername=x-access-token\npassword=env_token\n",
+		},
+		{
+			name:      "unknown host",
+			operation: "get",
+			input:     "protocol=https\nhost=example.com\n",
+			wantOut:   "",
+		},
+		{
+			name:      "not HTTPS",
+			operation: "get",
+			input:     "protocol=http\nhost=github.com\n",
+			wantOut:   "",
+		},
+		{
+			name:      "store",
+			operation: "store",
+			input:     "protocol=https\nhost=github.com\nusername=monalisa\npassword=new_token\n",
+			wantOut:   "",
+		},
+		{
+			name:      "malformed input",
+			operation: "get",
+			input:     "protocol\n",
+			wantErr:   `git: malformed credential attribute "protocol"`,
+		},
+		{
+			name:      "unsupported operation",
+			operation: "approve",
+			wantErr:   `git: unsupported credential operation "approve"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			out := &bytes.Buffer{}
+			err := RunCredentialHelper(tt.operation, strings.NewReader(tt.input), out)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
+
+func TestCredentialWrite(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := Credential{Protocol: "https", Host: "github.com", Password: "a\nb"}.Write(out)
+	assert.EqualError(t, err, "git: credential attribute password contains a newline or NUL byte")
+}
+
+func TestCredentialHelperArgs(t *testing.T) {
+	assert.Equal(t, []string{
+		"-c", "credential.helper=",
+		"-c", `credential.helper=!"/usr/bin/gh-ext" git-credential`,
+	}, CredentialHelperArgs(`"/usr/bin/gh-ext" git-credential`))
+}
+
+func stubConfig(t *testing.T, cfgStr string) {
+	t.Helper()
+	old := config.Read
+	config.Read = func(_ *config.Config) (*config.Config, error) {
+		return config.ReadFromString(cfgStr), nil
+	}
+	t.Cleanup(func() {
+		config.Read = old
+	})
+}
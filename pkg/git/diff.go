@@ -0,0 +1,317 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeaderRE = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// FileDiff is the difference of a single file, as returned by ParseDiff and ParseNumstat.
+type FileDiff struct {
+	// OldPath and NewPath are the paths of the file before and after the change.
+	// They are equal unless the file was renamed or copied, and one of them is empty
+	// if the file was created or deleted.
+	OldPath string
+	NewPath string
+
+	IsNew     bool
+	IsDeleted bool
+	IsRename  bool
+	IsCopy    bool
+	Binary    bool
+
+	// Added and Deleted are the number of added and deleted lines.
+	Added   int
+	Deleted int
+
+	// Hunks are the changed regions of the file. ParseNumstat does not return hunks.
+	Hunks []Hunk
+}
+
+// Hunk is a changed region of a file in a unified diff.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+
+	// Section is the text after the range information of the hunk header,
+	// typically the enclosing function.
+	Section string
+
+	Lines []DiffLine
+}
+
+// DiffLine is a line of a hunk.
+type DiffLine struct {
+	// Op is '+' for added, '-' for deleted, and ' ' for context lines.
+	Op byte
+
+	// Content is the line without Op and without the trailing newline.
+	Content string
+}
+
+// Diff runs git diff with the specified arguments, such as revisions or "--"
+// followed by paths, and returns the parsed differences.
+func (c *Client) Diff(ctx context.Context, args ...string) ([]FileDiff, error) {
+	args = append([]string{"diff", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/"}, args...)
+	out, err := c.Run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDiff(strings.NewReader(string(out)))
+}
+
+// DiffNumstat runs git diff --numstat with the specified arguments and returns the
+// number of added and deleted lines of each changed file, without hunks.
+func (c *Client) DiffNumstat(ctx context.Context, args ...string) ([]FileDiff, error) {
+	args = append([]string{"diff", "--numstat", "-z", "--no-color", "--no-ext-diff"}, args...)
+	out, err := c.Run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNumstat(strings.NewReader(string(out)))
+}
+
+// ParseDiff parses a unified diff, such as the output of git diff or git show.
+// Both diffs with git extended headers and plain unified diffs are supported.
+func ParseDiff(r io.Reader) ([]FileDiff, error) {
+	var files []*FileDiff
+	var file *FileDiff
+	var hunk *Hunk
+	oldLeft, newLeft := 0, 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+			if line == "" {
+				// Some tools strip the trailing space of empty context lines.
+				line = " "
+			}
+			switch line[0] {
+			case ' ':
+				oldLeft--
+				newLeft--
+			case '-':
+				oldLeft--
+				file.Deleted++
+			case '+':
+				newLeft--
+				file.Added++
+			case '\\':
+				// "\ No newline at end of file"
+				continue
+			default:
+				return nil, fmt.Errorf("git: malformed hunk line %q", line)
+			}
+			hunk.Lines = append(hunk.Lines, DiffLine{Op: line[0], Content: line[1:]})
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file = &FileDiff{}
+			file.OldPath, file.NewPath = parseGitDiffHeader(strings.TrimPrefix(line, "diff --git "))
+			files = append(files, file)
+			hunk = nil
+		case strings.HasPrefix(line, "--- "):
+			if file == nil || len(file.Hunks) > 0 {
+				// Plain unified diffs have no header line before the file names.
+				file = &FileDiff{}
+				files = append(files, file)
+				hunk = nil
+			}
+			path := parseDiffPath(strings.TrimPrefix(line, "--- "), "a/")
+			if path == "" {
+				file.IsNew = true
+			}
+			file.OldPath = path
+		case strings.HasPrefix(line, "+++ ") && file != nil:
+			path := parseDiffPath(strings.TrimPrefix(line, "+++ "), "b/")
+			if path == "" {
+				file.IsDeleted = true
+			}
+			file.NewPath = path
+		case strings.HasPrefix(line, "@@ "):
+			if file == nil {
+				return nil, fmt.Errorf("git: hunk without file header: %q", line)
+			}
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			file.Hunks = append(file.Hunks, h)
+			hunk = &file.Hunks[len(file.Hunks)-1]
+			oldLeft, newLeft = h.OldLines, h.NewLines
+		case file == nil || hunk != nil:
+			// Text before the first file or after the last hunk, such as commit messages.
+		case strings.HasPrefix(line, "new file mode "):
+			file.IsNew = true
+			file.OldPath = ""
+		case strings.HasPrefix(line, "deleted file mode "):
+			file.IsDeleted = true
+			file.NewPath = ""
+		case strings.HasPrefix(line, "rename from "):
+			file.IsRename = true
+			file.OldPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			file.IsRename = true
+			file.NewPath = unquotePath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy from "):
+			file.IsCopy = true
+			file.OldPath = unquotePath(strings.TrimPrefix(line, "copy from "))
+		case strings.HasPrefix(line, "copy to "):
+			file.IsCopy = true
+			file.NewPath = unquotePath(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			file.Binary = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]FileDiff, len(files))
+	for i, f := range files {
+		result[i] = *f
+	}
+	return result, nil
+}
+
+// ParseNumstat parses the output of git diff --numstat -z.
+func ParseNumstat(r io.Reader) ([]FileDiff, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Split(string(b), "\x00")
+
+	var files []FileDiff
+	for i := 0; i < len(fields); i++ {
+		if strings.Trim(fields[i], "\n") == "" {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimLeft(fields[i], "\n"), "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("git: malformed numstat entry %q", fields[i])
+		}
+		var f FileDiff
+		if parts[0] == "-" && parts[1] == "-" {
+			f.Binary = true
+		} else {
+			if f.Added, err = strconv.Atoi(parts[0]); err != nil {
+				return nil, fmt.Errorf("git: malformed numstat entry %q", fields[i])
+			}
+			if f.Deleted, err = strconv.Atoi(parts[1]); err != nil {
+				return nil, fmt.Errorf("git: malformed numstat entry %q", fields[i])
+			}
+		}
+		if parts[2] == "" {
+			// Renames and copies are followed by the old and new path as separate fields.
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("git: malformed numstat entry %q", fields[i])
+			}
+			f.OldPath, f.NewPath = fields[i+1], fields[i+2]
+			f.IsRename = true
+			i += 2
+		} else {
+			f.OldPath, f.NewPath = parts[2], parts[2]
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func parseHunkHeader(line string) (Hunk, error) {
+	m := hunkHeaderRE.FindStringSubmatch(line)
+	if m == nil {
+		return Hunk{}, fmt.Errorf("git: malformed hunk header %q", line)
+	}
+	atoi := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	return Hunk{
+		OldStart: atoi(m[1]),
+		OldLines: atoi(m[2]),
+		NewStart: atoi(m[3]),
+		NewLines: atoi(m[4]),
+		Section:  m[5],
+	}, nil
+}
+
+// parseGitDiffHeader returns the paths of a "diff --git a/OLD b/NEW" line. The paths
+// are ambiguous if they contain " b/", in which case equal paths are assumed.
+func parseGitDiffHeader(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) {
+		if oldPath, rest, ok := cutQuoted(s); ok {
+			return strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(unquotePath(strings.TrimPrefix(rest, " ")), "b/")
+		}
+	}
+	if n := len(s); n%2 == 1 {
+		oldPath, newPath := s[:n/2], s[n/2+1:]
+		if strings.HasPrefix(oldPath, "a/") && strings.HasPrefix(newPath, "b/") && oldPath[2:] == newPath[2:] {
+			return oldPath[2:], newPath[2:]
+		}
+	}
+	if idx := strings.Index(s, " b/"); idx >= 0 {
+		return strings.TrimPrefix(s[:idx], "a/"), unquotePath(s[idx+3:])
+	}
+	return s, s
+}
+
+// parseDiffPath returns the path of a "---" or "+++" line, or empty for /dev/null.
+func parseDiffPath(s, prefix string) string {
+	if strings.HasPrefix(s, `"`) {
+		if path, _, ok := cutQuoted(s); ok {
+			s = path
+		}
+	} else if idx := strings.IndexByte(s, '\t'); idx >= 0 {
+		// Plain unified diffs may follow the path with a timestamp.
+		s = s[:idx]
+	}
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, prefix)
+}
+
+// cutQuoted unquotes the C-style quoted string at the start of s
+// and returns the remainder of s.
+func cutQuoted(s string) (string, string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			unquoted, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", false
+			}
+			return unquoted, s[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// unquotePath unquotes paths that git quoted because they contain special characters.
+func unquotePath(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		if path, _, ok := cutQuoted(s); ok {
+			return path
+		}
+	}
+	return s
+}
@@ -0,0 +1,201 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		diff      string
+		wantFiles []FileDiff
+		wantErr   string
+	}{
+		{
+			name: "modified file",
+			diff: `diff --git a/main.go b/main.go
+index 1234567..89abcde 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@ package main
+ import "fmt"
+-func main() {
++func main()  {
+-- 
++--- 
+\ No newline at end of file
+`,
+			wantFiles: []FileDiff{{
+				OldPath: "main.go",
+				NewPath: "main.go",
+				Added:   2,
+				Deleted: 2,
+				Hunks: []Hunk{{
+					OldStart: 1, OldLines: 4, NewStart: 1, NewLines: 4,
+					Section: "package main",
+					Lines: []DiffLine{
+						{Op: ' ', Content: `import "fmt"`},
+						{Op: '-', Content: "func main() {"},
+						{Op: '+', Content: "func main()  {"},
+						{Op: '-', Content: "- "},
+						{Op: '+', Content: "--- "},
+					},
+				}},
+			}},
+		},
+		{
+			name: "new, deleted, renamed and binary files",
+			diff: `diff --git a/new file.txt b/new file.txt
+new file mode 100644
+index 0000000..3b18e51
+--- /dev/null
++++ b/new file.txt
+@@ -0,0 +1 @@
++hello
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 3b18e51..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-hello
+-
+diff --git a/docs/a.md b/docs/b.md
+similarity index 100%
+rename from docs/a.md
+rename to docs/b.md
+diff --git "a/caf\303\251.png" "b/caf\303\251.png"
+index 1234567..89abcde 100644
+Binary files "a/caf\303\251.png" and "b/caf\303\251.png" differ
+`,
+			wantFiles: []FileDiff{
+				{
+					NewPath: "new file.txt",
+					IsNew:   true,
+					Added:   1,
+					Hunks: []Hunk{{
+						OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1,
+						Lines: []DiffLine{{Op: '+', Content: "hello"}},
+					}},
+				},
+				{
+					OldPath:   "old.txt",
+					IsDeleted: true,
+					Deleted:   2,
+					Hunks: []Hunk{{
+						OldStart: 1, OldLines: 2, NewStart: 0, NewLines: 0,
+						Lines: []DiffLine{{Op: '-', Content: "hello"}, {Op: '-', Content: ""}},
+					}},
+				},
+				{OldPath: "docs/a.md", NewPath: "docs/b.md", IsRename: true},
+				{OldPath: "café.png", NewPath: "café.png", Binary: true},
+			},
+		},
+		{
+			name: "plain unified diff",
+			diff: `--- a.txt	2024-01-01 00:00:00
++++ b.txt	2024-01-02 00:00:00
+@@ -1 +1 @@
+-a
++b
+--- c.txt
++++ c.txt
+@@ -1 +1,2 @@
+ c
++d
+`,
+			wantFiles: []FileDiff{
+				{
+					OldPath: "a.txt", NewPath: "b.txt", Added: 1, Deleted: 1,
+					Hunks: []Hunk{{
+						OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1,
+						Lines: []DiffLine{{Op: '-', Content: "a"}, {Op: '+', Content: "b"}},
+					}},
+				},
+				{
+					OldPath: "c.txt", NewPath: "c.txt", Added: 1,
+					Hunks: []Hunk{{
+						OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 2,
+						Lines: []DiffLine{{Op: ' ', Content: "c"}, {Op: '+', Content: "d"}},
+					}},
+				},
+			},
+		},
+		{
+			name:    "malformed hunk header",
+			diff:    "--- a\n+++ b\n@@ -x +1 @@\n",
+			wantErr: `git: malformed hunk header "@@ -x +1 @@"`,
+		},
+		{
+			name:    "malformed hunk line",
+			diff:    "--- a\n+++ b\n@@ -1 +1 @@\n*a\n",
+			wantErr: `git: malformed hunk line "*a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ParseDiff(strings.NewReader(tt.diff))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFiles, files)
+		})
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	out := "3\t1\tmain.go\x00-\t-\timage.png\x000\t0\t\x00docs/a.md\x00docs/b.md\x00"
+	files, err := ParseNumstat(strings.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, []FileDiff{
+		{OldPath: "main.go", NewPath: "main.go", Added: 3, Deleted: 1},
+		{OldPath: "image.png", NewPath: "image.png", Binary: true},
+		{OldPath: "docs/a.md", NewPath: "docs/b.md", IsRename: true},
+	}, files)
+
+	_, err = ParseNumstat(strings.NewReader("x\t1\tmain.go\x00"))
+	assert.EqualError(t, err, `git: malformed numstat entry "x\t1\tmain.go"`)
+}
+
+func TestClientDiff(t *testing.T) {
+	ctx := context.Background()
+	c := newTestRepo(t)
+	run(t, c, "config", "diff.noprefix", "true")
+	writeFile(t, c, "README.md", "hello\nworld\n")
+	writeFile(t, c, "added.txt", "added\n")
+	run(t, c, "add", "added.txt")
+
+	files, err := c.Diff(ctx, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []FileDiff{
+		{
+			OldPath: "README.md", NewPath: "README.md", Added: 1,
+			Hunks: []Hunk{{
+				OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 2,
+				Lines: []DiffLine{{Op: ' ', Content: "hello"}, {Op: '+', Content: "world"}},
+			}},
+		},
+		{
+			NewPath: "added.txt", IsNew: true, Added: 1,
+			Hunks: []Hunk{{
+				OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1,
+				Lines: []DiffLine{{Op: '+', Content: "added"}},
+			}},
+		},
+	}, files)
+
+	files, err = c.DiffNumstat(ctx, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []FileDiff{
+		{OldPath: "README.md", NewPath: "README.md", Added: 1},
+		{OldPath: "added.txt", NewPath: "added.txt", Added: 1},
+	}, files)
+}
@@ -0,0 +1,18 @@
+package git
+
+import (
+	"errors"
+
+	internalgit "github.com/cli/go-gh/v2/internal/git"
+)
+
+// ErrNotOnAnyBranch is returned by CurrentBranch when HEAD is detached.
+var ErrNotOnAnyBranch = errors.New("git: not on any branch")
+
+// ErrConfigNotFound is returned by Config when the key is not set.
+var ErrConfigNotFound = errors.New("git: config key not found")
+
+// GitError represents a git command that failed to run or exited with a non-zero status.
+// Its Args, ExitCode and Stderr fields hold the arguments git was run with, the exit code
+// of git or -1 if git could not be run, and the standard error output of git.
+type GitError = internalgit.Error
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Fields of a commit are separated by NUL bytes, which can not appear in commit
+// messages, and commits are separated by the ASCII record separator.
+const logFormat = "%x1e%H%x00%P%x00%an%x00%ae%x00%aI%x00%cn%x00%ce%x00%cI%x00%s%x00%b%x00%(trailers:only,unfold)"
+
+const logFields = 11
+
+// Commit is a commit returned by Log.
+type Commit struct {
+	SHA       string
+	Parents   []string
+	Author    Signature
+	Committer Signature
+
+	// Subject is the first paragraph of the commit message, joined into a single line.
+	Subject string
+
+	// Body is the commit message after the subject, including any trailers.
+	Body string
+
+	// Trailers are the trailers at the end of the commit message,
+	// such as "Co-authored-by: Mona Lisa <monalisa@github.com>", in order.
+	Trailers []Trailer
+}
+
+// Signature identifies the author or committer of a commit.
+type Signature struct {
+	Name  string
+	Email string
+	Date  time.Time
+}
+
+// Trailer is a "Key: value" line at the end of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// CoAuthors returns the values of the Co-authored-by trailers of the commit.
+func (c Commit) CoAuthors() []string {
+	var coAuthors []string
+	for _, t := range c.Trailers {
+		if strings.EqualFold(t.Key, "Co-authored-by") {
+			coAuthors = append(coAuthors, t.Value)
+		}
+	}
+	return coAuthors
+}
+
+// Log runs git log with the specified arguments, such as a revision range, a
+// limit like "-n", "10", or "--" followed by paths, and returns the commits.
+// Arguments that change the output format must not be used.
+func (c *Client) Log(ctx context.Context, args ...string) ([]Commit, error) {
+	args = append([]string{"log", "--no-color", "--no-show-signature", "--format=" + logFormat}, args...)
+	out, err := c.Run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(string(out))
+}
+
+func parseLog(out string) ([]Commit, error) {
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		if record == "" {
+			continue
+		}
+		fields := strings.Split(record, "\x00")
+		if len(fields) != logFields {
+			return nil, fmt.Errorf("git: unexpected log output: expected %d fields, got %d", logFields, len(fields))
+		}
+		author, err := parseSignature(fields[2], fields[3], fields[4])
+		if err != nil {
+			return nil, err
+		}
+		committer, err := parseSignature(fields[5], fields[6], fields[7])
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, Commit{
+			SHA:       fields[0],
+			Parents:   strings.Fields(fields[1]),
+			Author:    author,
+			Committer: committer,
+			Subject:   fields[8],
+			Body:      strings.TrimRight(fields[9], "\n"),
+			Trailers:  parseTrailers(fields[10]),
+		})
+	}
+	return commits, nil
+}
+
+func parseSignature(name, email, date string) (Signature, error) {
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return Signature{}, fmt.Errorf("git: invalid commit date %q: %w", date, err)
+	}
+	return Signature{Name: name, Email: email, Date: t}, nil
+}
+
+func parseTrailers(s string) []Trailer {
+	var trailers []Trailer
+	for _, line := range strings.Split(s, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		trailers = append(trailers, Trailer{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return trailers
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientLog(t *testing.T) {
+	ctx := context.Background()
+	c := newTestRepo(t)
+	c.Env = append(c.Env,
+		"GIT_AUTHOR_DATE=2024-01-02T03:04:05+01:00",
+		"GIT_COMMITTER_DATE=2024-01-03T00:00:00Z",
+	)
+	writeFile(t, c, "feature.txt", "feature\n")
+	run(t, c, "add", "feature.txt")
+	run(t, c, "commit", "--quiet",
+		"-m", "Add feature\nwith a long subject",
+		"-m", "The body explains\nthe change.",
+		"-m", "Co-authored-by: Hubot <hubot@github.com>\nReviewed-by: Octocat <octocat@github.com>")
+
+	commits, err := c.Log(ctx, "-n", "5")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+
+	head, initial := commits[0], commits[1]
+	assert.Len(t, head.SHA, 40)
+	assert.Equal(t, []string{initial.SHA}, head.Parents)
+	assert.Empty(t, initial.Parents)
+	assert.Equal(t, "Mona Lisa", head.Author.Name)
+	assert.Equal(t, "monalisa@github.com", head.Author.Email)
+	assert.True(t, head.Author.Date.Equal(time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC)))
+	assert.True(t, head.Committer.Date.Equal(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "Add feature with a long subject", head.Subject)
+	assert.Equal(t, "The body explains\nthe change.\n\nCo-authored-by: Hubot <hubot@github.com>\nReviewed-by: Octocat <octocat@github.com>", head.Body)
+	assert.Equal(t, []Trailer{
+		{Key: "Co-authored-by", Value: "Hubot <hubot@github.com>"},
+		{Key: "Reviewed-by", Value: "Octocat <octocat@github.com>"},
+	}, head.Trailers)
+	assert.Equal(t, []string{"Hubot <hubot@github.com>"}, head.CoAuthors())
+	assert.Equal(t, "initial commit", initial.Subject)
+	assert.Equal(t, "", initial.Body)
+	assert.Empty(t, initial.Trailers)
+
+	commits, err = c.Log(ctx, "HEAD~1..HEAD", "--", "README.md")
+	assert.NoError(t, err)
+	assert.Empty(t, commits)
+}
+
+func TestParseLogError(t *testing.T) {
+	_, err := parseLog("\x1eabc\x00def")
+	assert.EqualError(t, err, "git: unexpected log output: expected 11 fields, got 2")
+}
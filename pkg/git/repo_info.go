@@ -0,0 +1,96 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository is returned by RepoInfo when the directory of the client
+// is not within a git repository.
+var ErrNotRepository = errors.New("git: not a git repository")
+
+// RepoInfo describes the layout of the repository containing the directory of a Client.
+// All paths are absolute.
+type RepoInfo struct {
+	// TopLevel is the root directory of the working tree,
+	// or empty for bare repositories.
+	TopLevel string
+
+	// GitDir is the git directory of the working tree. It is the .git directory for
+	// a regular checkout, a directory within the .git/worktrees directory of the main
+	// working tree for a linked worktree, and a directory within the .git/modules
+	// directory of the superproject for a submodule.
+	GitDir string
+
+	// CommonDir is the git directory shared by all worktrees of the repository,
+	// which holds its configuration, refs, and objects.
+	CommonDir string
+
+	// IsBare is true for repositories without a working tree.
+	IsBare bool
+
+	// IsWorktree is true for linked worktrees created with "git worktree add".
+	IsWorktree bool
+
+	// SuperProject is the root directory of the working tree of the superproject
+	// if the repository is a submodule, or empty otherwise.
+	SuperProject string
+}
+
+// RepoInfo detects the repository containing the directory of the client.
+// If the directory is not within a repository the error is ErrNotRepository.
+func (c *Client) RepoInfo(ctx context.Context) (RepoInfo, error) {
+	var info RepoInfo
+
+	// --path-format=absolute would avoid resolving the common dir, but requires git 2.31.
+	lines, err := c.RevParse(ctx, "--is-bare-repository", "--absolute-git-dir", "--git-common-dir")
+	if err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "not a git repository") {
+			return info, ErrNotRepository
+		}
+		return info, err
+	}
+	if len(lines) != 3 {
+		return info, errors.New("git: unexpected rev-parse output")
+	}
+	info.IsBare = lines[0] == "true"
+	info.GitDir = filepath.Clean(lines[1])
+	info.CommonDir, err = c.absPath(lines[2])
+	if err != nil {
+		return info, err
+	}
+	info.IsWorktree = info.GitDir != info.CommonDir && filepath.Base(filepath.Dir(info.GitDir)) == "worktrees"
+
+	if info.IsBare {
+		return info, nil
+	}
+
+	lines, err = c.RevParse(ctx, "--show-toplevel", "--show-superproject-working-tree")
+	if err != nil {
+		return info, err
+	}
+	info.TopLevel = filepath.Clean(lines[0])
+	if len(lines) > 1 {
+		info.SuperProject = filepath.Clean(lines[1])
+	}
+	return info, nil
+}
+
+// absPath resolves a path printed by git relative to the directory of the client,
+// following symbolic links like the paths git prints in absolute form.
+func (c *Client) absPath(p string) (string, error) {
+	if !filepath.IsAbs(p) {
+		dir, err := filepath.Abs(c.Dir)
+		if err != nil {
+			return "", err
+		}
+		p = filepath.Join(dir, p)
+	}
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		return resolved, nil
+	}
+	return filepath.Clean(p), nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/internal/git"
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/cli/go-gh/v2/pkg/ssh"
+)
+
+const (
+	resolvedBase  = "base"
+	resolvedOther = "other"
+)
+
+// ForkResolver looks up the parent of a repository in its fork network.
+// *api.ClientRegistry implements ForkResolver.
+type ForkResolver interface {
+	// RepositoryParent returns the parent of the specified repository,
+	// and false if the repository is not a fork.
+	RepositoryParent(ctx context.Context, repo Repository) (Repository, bool, error)
+}
+
+// ForkResolverFunc is an adapter to allow the use of ordinary functions as ForkResolver.
+type ForkResolverFunc func(ctx context.Context, repo Repository) (Repository, bool, error)
+
+// RepositoryParent calls f(ctx, repo).
+func (f ForkResolverFunc) RepositoryParent(ctx context.Context, repo Repository) (Repository, bool, error) {
+	return f(ctx, repo)
+}
+
+// NetworkOptions holds optional configuration for CurrentNetwork.
+type NetworkOptions struct {
+	// ForkResolver is used to look up the parent of the head repository when no
+	// base repository has been chosen with gh. Default is to not query the API,
+	// in which case the base repository is chosen from the git remotes.
+	ForkResolver ForkResolver
+
+	// Dir is the directory to read git remotes from.
+	// Default is the current directory.
+	Dir string
+}
+
+// Network holds the repositories a pull request oriented tool works with.
+type Network struct {
+	// Base is the repository pull requests are opened against.
+	Base Repository
+
+	// BaseRemote is the name of the git remote pointing to Base,
+	// or empty if no remote points to it.
+	BaseRemote string
+
+	// Head is the repository branches are pushed to, typically a fork of Base.
+	Head Repository
+
+	// HeadRemote is the name of the git remote pointing to Head,
+	// or empty if the repositories were specified with GH_REPO.
+	HeadRemote string
+}
+
+// CurrentNetwork uses git remotes to determine the base and head GitHub repositories
+// of the current directory, or of opts.Dir if specified, the same way gh does.
+// The base repository is determined in the following order:
+// - GH_REPO environment variable, which is also used as the head repository;
+// - Remote marked as "base" with gh-resolved, as set by "gh repo set-default";
+// - Repository named by a gh-resolved value of the "OWNER/REPO" format;
+// - Parent of the head repository, if opts.ForkResolver is specified and the head repository is a fork;
+// - First remote in the order used by Current.
+//
+// The head repository is the remote named "origin", falling back to the first remote.
+func CurrentNetwork(ctx context.Context, opts NetworkOptions) (Network, error) {
+	if override := os.Getenv("GH_REPO"); override != "" {
+		r, err := Parse(override)
+		if err != nil {
+			return Network{}, err
+		}
+		return Network{Base: r, Head: r}, nil
+	}
+
+	remotes, err := currentRemotes(opts.Dir)
+	if err != nil {
+		return Network{}, err
+	}
+	return resolveNetwork(ctx, remotes, opts)
+}
+
+func resolveNetwork(ctx context.Context, remotes git.RemoteSet, opts NetworkOptions) (Network, error) {
+	var n Network
+
+	head := remotes[0]
+	for _, rem := range remotes {
+		if rem.Name == "origin" {
+			head = rem
+			break
+		}
+	}
+	n.Head = remoteRepository(head)
+	n.HeadRemote = head.Name
+
+	for _, rem := range remotes {
+		if rem.Resolved == resolvedBase {
+			n.Base = remoteRepository(rem)
+			n.BaseRemote = rem.Name
+			return n, nil
+		}
+	}
+
+	for _, rem := range remotes {
+		if rem.Resolved == "" || rem.Resolved == resolvedOther {
+			continue
+		}
+		base, err := ParseWithHost(rem.Resolved, rem.Host)
+		if err != nil {
+			continue
+		}
+		n.Base = base
+		n.BaseRemote = findRemote(remotes, base)
+		return n, nil
+	}
+
+	if opts.ForkResolver != nil {
+		parent, isFork, err := opts.ForkResolver.RepositoryParent(ctx, n.Head)
+		if err != nil {
+			return n, err
+		}
+		if isFork {
+			n.Base = parent
+			n.BaseRemote = findRemote(remotes, parent)
+			return n, nil
+		}
+	}
+
+	n.Base = remoteRepository(remotes[0])
+	n.BaseRemote = remotes[0].Name
+	return n, nil
+}
+
+// currentRemotes returns the git remotes of the specified directory that point to
+// known GitHub hosts, in order of preference.
+func currentRemotes(dir string) (git.RemoteSet, error) {
+	remotes, err := git.RemotesInDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(remotes) == 0 {
+		return nil, errors.New("unable to determine current repository, no git remotes configured for this repository")
+	}
+
+	translator := ssh.NewTranslator()
+	for _, r := range remotes {
+		if r.FetchURL != nil {
+			r.FetchURL = translator.Translate(r.FetchURL)
+		}
+		if r.PushURL != nil {
+			r.PushURL = translator.Translate(r.PushURL)
+		}
+	}
+
+	hosts := auth.KnownHosts()
+
+	filteredRemotes := remotes.FilterByHosts(hosts)
+	if len(filteredRemotes) == 0 {
+		return nil, errors.New("unable to determine current repository, none of the git remotes configured for this repository point to a known GitHub host")
+	}
+	return filteredRemotes, nil
+}
+
+func remoteRepository(rem *git.Remote) Repository {
+	return Repository{Host: rem.Host, Owner: rem.Owner, Name: rem.Repo}
+}
+
+// findRemote returns the name of the first remote pointing to repo.
+func findRemote(remotes git.RemoteSet, repo Repository) string {
+	for _, rem := range remotes {
+		if strings.EqualFold(rem.Host, repo.Host) &&
+			strings.EqualFold(rem.Owner, repo.Owner) &&
+			strings.EqualFold(rem.Repo, repo.Name) {
+			return rem.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cli/go-gh/v2/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveNetwork(t *testing.T) {
+	upstream := Repository{Host: "github.com", Owner: "cli", Name: "go-gh"}
+	fork := Repository{Host: "github.com", Owner: "monalisa", Name: "go-gh"}
+	parentOf := func(parent Repository) ForkResolver {
+		return ForkResolverFunc(func(_ context.Context, repo Repository) (Repository, bool, error) {
+			assert.Equal(t, fork, repo)
+			return parent, parent != Repository{}, nil
+		})
+	}
+
+	tests := []struct {
+		name        string
+		remotes     git.RemoteSet
+		opts        NetworkOptions
+		wantNetwork Network
+		wantErr     string
+	}{
+		{
+			name: "first remote without resolution",
+			remotes: git.RemoteSet{
+				{Name: "upstream", Host: "github.com", Owner: "cli", Repo: "go-gh"},
+				{Name: "origin", Host: "github.com", Owner: "monalisa", Repo: "go-gh"},
+			},
+			wantNetwork: Network{Base: upstream, BaseRemote: "upstream", Head: fork, HeadRemote: "origin"},
+		},
+		{
+			name: "remote resolved as base",
+			remotes: git.RemoteSet{
+				{Name: "upstream", Host: "github.com", Owner: "other", Repo: "go-gh", Resolved: "other"},
+				{Name: "origin", Host: "github.com", Owner: "monalisa", Repo: "go-gh", Resolved: "base"},
+			},
+			opts:        NetworkOptions{ForkResolver: parentOf(upstream)},
+			wantNetwork: Network{Base: fork, BaseRemote: "origin", Head: fork, HeadRemote: "origin"},
+		},
+		{
+			name: "remote resolved to explicit repository",
+			remotes: git.RemoteSet{
+				{Name: "origin", Host: "github.com", Owner: "monalisa", Repo: "go-gh", Resolved: "cli/go-gh"},
+			},
+			wantNetwork: Network{Base: upstream, BaseRemote: "", Head: fork, HeadRemote: "origin"},
+		},
+		{
+			name: "parent of fork",
+			remotes: git.RemoteSet{
+				{Name: "github", Host: "github.com", Owner: "cli", Repo: "go-gh"},
+				{Name: "origin", Host: "github.com", Owner: "monalisa", Repo: "go-gh"},
+			},
+			opts:        NetworkOptions{ForkResolver: parentOf(upstream)},
+			wantNetwork: Network{Base: upstream, BaseRemote: "github", Head: fork, HeadRemote: "origin"},
+		},
+		{
+			name: "not a fork",
+			remotes: git.RemoteSet{
+				{Name: "fork", Host: "github.com", Owner: "monalisa", Repo: "go-gh"},
+			},
+			opts:        NetworkOptions{ForkResolver: parentOf(Repository{})},
+			wantNetwork: Network{Base: fork, BaseRemote: "fork", Head: fork, HeadRemote: "fork"},
+		},
+		{
+			name: "fork resolver error",
+			remotes: git.RemoteSet{
+				{Name: "origin", Host: "github.com", Owner: "monalisa", Repo: "go-gh"},
+			},
+			opts: NetworkOptions{ForkResolver: ForkResolverFunc(func(context.Context, Repository) (Repository, bool, error) {
+				return Repository{}, false, errors.New("HTTP 404: Not Found")
+			})},
+			wantErr: "HTTP 404: Not Found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := resolveNetwork(context.Background(), tt.remotes, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNetwork, n)
+		})
+	}
+}
+
+func TestCurrentNetwork_override(t *testing.T) {
+	t.Setenv("GH_REPO", "example.com/OWNER/REPO")
+	n, err := CurrentNetwork(context.Background(), NetworkOptions{})
+	assert.NoError(t, err)
+	r := Repository{Host: "example.com", Owner: "OWNER", Name: "REPO"}
+	assert.Equal(t, Network{Base: r, Head: r}, n)
+}
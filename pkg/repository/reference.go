@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/internal/git"
+)
+
+// ReferenceKind describes what a Reference points to within a repository.
+type ReferenceKind int
+
+const (
+	// ReferenceRepository is the repository itself.
+	ReferenceRepository ReferenceKind = iota
+	// ReferenceTree is a ref, optionally with a directory path, such as "/tree/main/docs" or "OWNER/REPO@main".
+	ReferenceTree
+	// ReferenceBlob is a file at a ref, such as "/blob/main/README.md".
+	ReferenceBlob
+	// ReferenceCommit is a commit, such as "/commit/SHA".
+	ReferenceCommit
+	// ReferencePullRequest is a pull request, such as "/pull/123".
+	ReferencePullRequest
+	// ReferenceIssue is an issue, such as "/issues/123".
+	ReferenceIssue
+	// ReferenceIssueOrPullRequest is an issue or pull request number such as "OWNER/REPO#123".
+	// Issues and pull requests share numbers, so which one it is can not be determined without the API.
+	ReferenceIssueOrPullRequest
+)
+
+func (k ReferenceKind) String() string {
+	switch k {
+	case ReferenceRepository:
+		return "repository"
+	case ReferenceTree:
+		return "tree"
+	case ReferenceBlob:
+		return "blob"
+	case ReferenceCommit:
+		return "commit"
+	case ReferencePullRequest:
+		return "pull request"
+	case ReferenceIssue:
+		return "issue"
+	case ReferenceIssueOrPullRequest:
+		return "issue or pull request"
+	default:
+		return fmt.Sprintf("ReferenceKind(%d)", int(k))
+	}
+}
+
+// Reference is a reference to a repository or to something within it.
+type Reference struct {
+	Repository Repository
+	Kind       ReferenceKind
+
+	// Number is the issue or pull request number.
+	Number int
+
+	// Ref is the branch, tag, or commit SHA. Branches containing slashes can not be told
+	// apart from the path in web URLs, so the first path segment is always taken as the ref.
+	Ref string
+
+	// Path is the file or directory path within the repository.
+	Path string
+
+	// StartLine and EndLine are the highlighted lines of a blob, or zero if none are.
+	StartLine int
+	EndLine   int
+}
+
+// ParseReference extracts a reference from the following string formats:
+// "[HOST/]OWNER/REPO", "[HOST/]OWNER/REPO#NUMBER", "[HOST/]OWNER/REPO@REF",
+// git remote URLs, and web URLs of a repository, tree, blob, commit, pull
+// request, or issue such as "https://github.com/OWNER/REPO/blob/REF/PATH#L10-L20".
+// If the format does not specify a host, use the config to determine a host.
+func ParseReference(s string) (Reference, error) {
+	return ParseReferenceWithOptions(s, ParseOptions{})
+}
+
+// ParseReferenceWithHost extracts a reference from the string formats
+// supported by ParseReference. If the format does not specify a host,
+// use the host provided.
+func ParseReferenceWithHost(s, host string) (Reference, error) {
+	return ParseReferenceWithOptions(s, ParseOptions{Host: host})
+}
+
+// URL returns the web URL of the reference.
+func (ref Reference) URL() string {
+	r := ref.Repository
+	switch ref.Kind {
+	case ReferenceTree:
+		if ref.Path == "" {
+			return r.WebURL("tree/" + escapePath(ref.Ref))
+		}
+		return r.WebURL(fmt.Sprintf("tree/%s/%s", escapePath(ref.Ref), escapePath(ref.Path)))
+	case ReferenceBlob:
+		return r.FileURL(ref.Ref, ref.Path, ref.StartLine, ref.EndLine)
+	case ReferenceCommit:
+		return r.WebURL("commit/" + escapePath(ref.Ref))
+	case ReferencePullRequest:
+		return r.PullRequestURL(ref.Number)
+	case ReferenceIssue, ReferenceIssueOrPullRequest:
+		// Issue URLs redirect to the pull request if the number belongs to one.
+		return r.IssueURL(ref.Number)
+	default:
+		return r.URL()
+	}
+}
+
+// ParseReferenceWithOptions extracts a reference from the string formats
+// supported by ParseReference. See ParseOptions for the available configuration.
+func ParseReferenceWithOptions(s string, opts ParseOptions) (Reference, error) {
+	var ref Reference
+
+	s, err := rewriteURL(s, opts)
+	if err != nil {
+		return ref, err
+	}
+	// The rules are only applied once.
+	opts.RewriteURLs = false
+
+	if git.IsURL(s) {
+		u, err := git.ParseURL(s)
+		if err != nil {
+			return ref, err
+		}
+		if u.Scheme == "http" || u.Scheme == "https" {
+			return parseWebURL(u, opts)
+		}
+		ref.Repository, err = ParseWithOptions(s, opts)
+		return ref, err
+	}
+
+	repo := s
+	if idx := strings.LastIndex(s, "#"); idx >= 0 {
+		repo = s[:idx]
+		n, err := parseNumber(s[idx+1:])
+		if err != nil {
+			return ref, fmt.Errorf("invalid issue or pull request number in %q: %w", s, err)
+		}
+		ref.Kind = ReferenceIssueOrPullRequest
+		ref.Number = n
+	} else if idx := strings.Index(s, "@"); idx >= 0 {
+		repo = s[:idx]
+		ref.Ref = s[idx+1:]
+		if ref.Ref == "" {
+			return ref, fmt.Errorf("empty ref in %q", s)
+		}
+		ref.Kind = ReferenceTree
+	}
+
+	r, err := ParseWithOptions(repo, opts)
+	if err != nil {
+		return ref, err
+	}
+	ref.Repository = r
+	return ref, nil
+}
+
+func parseWebURL(u *url.URL, opts ParseOptions) (Reference, error) {
+	var ref Reference
+
+	host, owner, name, err := git.RepoInfoFromURLWithOptions(u, git.RepoURLOptions{
+		BasePaths:         opts.BasePaths,
+		KeepPort:          opts.KeepPort,
+		AllowTrailingPath: true,
+	})
+	if err != nil {
+		return ref, err
+	}
+	ref.Repository = Repository{Host: host, Owner: owner, Name: name}
+	if err := validate(ref.Repository, opts); err != nil {
+		return ref, err
+	}
+
+	// The base path was matched above, so the owner and name follow it.
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if base := strings.Trim(git.BasePath(u, opts.BasePaths), "/"); base != "" {
+		segments = segments[len(strings.Split(base, "/")):]
+	}
+	if len(segments) == 2 {
+		return ref, nil
+	}
+
+	rest := segments[3:]
+	switch segments[2] {
+	case "tree", "blob":
+		if len(rest) == 0 || rest[0] == "" {
+			return ref, fmt.Errorf("no ref in %q", u.String())
+		}
+		ref.Kind = ReferenceTree
+		ref.Ref = rest[0]
+		ref.Path = strings.Join(rest[1:], "/")
+		if segments[2] == "blob" {
+			if ref.Path == "" {
+				return ref, fmt.Errorf("no file path in %q", u.String())
+			}
+			ref.Kind = ReferenceBlob
+			ref.StartLine, ref.EndLine, err = parseLineRange(u.Fragment)
+			if err != nil {
+				return ref, err
+			}
+		}
+	case "commit":
+		if len(rest) == 0 || rest[0] == "" {
+			return ref, fmt.Errorf("no commit in %q", u.String())
+		}
+		ref.Kind = ReferenceCommit
+		ref.Ref = rest[0]
+	case "pull", "issues":
+		ref.Kind = ReferencePullRequest
+		if segments[2] == "issues" {
+			ref.Kind = ReferenceIssue
+		}
+		if len(rest) == 0 {
+			return ref, fmt.Errorf("no %s number in %q", ref.Kind, u.String())
+		}
+		ref.Number, err = parseNumber(rest[0])
+		if err != nil {
+			return ref, fmt.Errorf("invalid %s number in %q: %w", ref.Kind, u.String(), err)
+		}
+	default:
+		return ref, fmt.Errorf("unsupported repository URL path: %s", u.Path)
+	}
+
+	return ref, nil
+}
+
+func parseNumber(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a positive number", s)
+	}
+	return n, nil
+}
+
+// parseLineRange parses line anchors of the form "L10" and "L10-L20".
+// Column positions such as "L10C5" are ignored.
+func parseLineRange(fragment string) (int, int, error) {
+	if !strings.HasPrefix(fragment, "L") {
+		return 0, 0, nil
+	}
+	start, end, isRange := strings.Cut(fragment[1:], "-")
+	start, _, _ = strings.Cut(start, "C")
+	end, _, _ = strings.Cut(end, "C")
+	startLine, err := parseNumber(start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line anchor %q: %w", fragment, err)
+	}
+	if !isRange {
+		return startLine, 0, nil
+	}
+	endLine, err := parseNumber(strings.TrimPrefix(end, "L"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line anchor %q: %w", fragment, err)
+	}
+	return startLine, endLine, nil
+}
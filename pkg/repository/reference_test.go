@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	stubConfig(t, "")
+
+	repo := Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"}
+	tests := []struct {
+		name    string
+		input   string
+		wantRef Reference
+		wantURL string
+		wantErr string
+	}{
+		{
+			name:    "OWNER/REPO",
+			input:   "OWNER/REPO",
+			wantRef: Reference{Repository: repo},
+			wantURL: "https://github.com/OWNER/REPO",
+		},
+		{
+			name:    "OWNER/REPO#NUMBER",
+			input:   "OWNER/REPO#123",
+			wantRef: Reference{Repository: repo, Kind: ReferenceIssueOrPullRequest, Number: 123},
+			wantURL: "https://github.com/OWNER/REPO/issues/123",
+		},
+		{
+			name:  "HOST/OWNER/REPO@REF",
+			input: "example.com/OWNER/REPO@feature/one",
+			wantRef: Reference{
+				Repository: Repository{Host: "example.com", Owner: "OWNER", Name: "REPO"},
+				Kind:       ReferenceTree,
+				Ref:        "feature/one",
+			},
+			wantURL: "https://example.com/OWNER/REPO/tree/feature/one",
+		},
+		{
+			name:    "git URL",
+			input:   "git@github.com:OWNER/REPO.git",
+			wantRef: Reference{Repository: repo},
+			wantURL: "https://github.com/OWNER/REPO",
+		},
+		{
+			name:    "repository URL",
+			input:   "https://www.github.com/OWNER/REPO/",
+			wantRef: Reference{Repository: repo},
+			wantURL: "https://github.com/OWNER/REPO",
+		},
+		{
+			name:    "tree URL",
+			input:   "https://github.com/OWNER/REPO/tree/main/docs/guides",
+			wantRef: Reference{Repository: repo, Kind: ReferenceTree, Ref: "main", Path: "docs/guides"},
+			wantURL: "https://github.com/OWNER/REPO/tree/main/docs/guides",
+		},
+		{
+			name:    "blob URL",
+			input:   "https://github.com/OWNER/REPO/blob/abc123/cmd/main.go",
+			wantRef: Reference{Repository: repo, Kind: ReferenceBlob, Ref: "abc123", Path: "cmd/main.go"},
+			wantURL: "https://github.com/OWNER/REPO/blob/abc123/cmd/main.go",
+		},
+		{
+			name:  "blob URL with line range",
+			input: "https://github.com/OWNER/REPO/blob/abc123/main.go#L10-L20",
+			wantRef: Reference{
+				Repository: repo, Kind: ReferenceBlob, Ref: "abc123", Path: "main.go", StartLine: 10, EndLine: 20,
+			},
+			wantURL: "https://github.com/OWNER/REPO/blob/abc123/main.go#L10-L20",
+		},
+		{
+			name:  "blob URL with line and column",
+			input: "https://github.com/OWNER/REPO/blob/abc123/main.go#L10C3",
+			wantRef: Reference{
+				Repository: repo, Kind: ReferenceBlob, Ref: "abc123", Path: "main.go", StartLine: 10,
+			},
+			wantURL: "https://github.com/OWNER/REPO/blob/abc123/main.go#L10",
+		},
+		{
+			name:    "commit URL",
+			input:   "https://github.com/OWNER/REPO/commit/abc123",
+			wantRef: Reference{Repository: repo, Kind: ReferenceCommit, Ref: "abc123"},
+			wantURL: "https://github.com/OWNER/REPO/commit/abc123",
+		},
+		{
+			name:    "pull request URL",
+			input:   "https://github.com/OWNER/REPO/pull/123/files",
+			wantRef: Reference{Repository: repo, Kind: ReferencePullRequest, Number: 123},
+			wantURL: "https://github.com/OWNER/REPO/pull/123",
+		},
+		{
+			name:  "enterprise issue URL",
+			input: "https://example.com/OWNER/REPO/issues/45",
+			wantRef: Reference{
+				Repository: Repository{Host: "example.com", Owner: "OWNER", Name: "REPO"},
+				Kind:       ReferenceIssue,
+				Number:     45,
+			},
+			wantURL: "https://example.com/OWNER/REPO/issues/45",
+		},
+		{
+			name:    "invalid number",
+			input:   "OWNER/REPO#abc",
+			wantErr: `invalid issue or pull request number in "OWNER/REPO#abc": "abc" is not a positive number`,
+		},
+		{
+			name:    "empty ref",
+			input:   "OWNER/REPO@",
+			wantErr: `empty ref in "OWNER/REPO@"`,
+		},
+		{
+			name:    "invalid repository",
+			input:   "OWNER#1",
+			wantErr: `expected the "[HOST/]OWNER/REPO" format, got "OWNER"`,
+		},
+		{
+			name:    "blob URL without path",
+			input:   "https://github.com/OWNER/REPO/blob/main",
+			wantErr: `no file path in "https://github.com/OWNER/REPO/blob/main"`,
+		},
+		{
+			name:    "pull request URL without number",
+			input:   "https://github.com/OWNER/REPO/pull",
+			wantErr: `no pull request number in "https://github.com/OWNER/REPO/pull"`,
+		},
+		{
+			name:    "invalid line anchor",
+			input:   "https://github.com/OWNER/REPO/blob/main/main.go#Lx",
+			wantErr: `invalid line anchor "Lx": "x" is not a positive number`,
+		},
+		{
+			name:    "unsupported URL path",
+			input:   "https://github.com/OWNER/REPO/wiki/Home",
+			wantErr: "unsupported repository URL path: /OWNER/REPO/wiki/Home",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseReference(tt.input)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRef, ref)
+			assert.Equal(t, tt.wantURL, ref.URL())
+		})
+	}
+}
+
+func TestParseReferenceWithHost(t *testing.T) {
+	ref, err := ParseReferenceWithHost("OWNER/REPO#7", "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, Reference{
+		Repository: Repository{Host: "example.com", Owner: "OWNER", Name: "REPO"},
+		Kind:       ReferenceIssueOrPullRequest,
+		Number:     7,
+	}, ref)
+}
+
+func TestParseReferenceWithOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    ParseOptions
+		wantRef Reference
+		wantErr string
+	}{
+		{
+			name:  "web URL with base path",
+			input: "https://example.com/github/OWNER/REPO/pull/1",
+			opts:  ParseOptions{BasePaths: map[string]string{"example.com": "/github"}},
+			wantRef: Reference{
+				Repository: Repository{Host: "example.com", Owner: "OWNER", Name: "REPO"},
+				Kind:       ReferencePullRequest,
+				Number:     1,
+			},
+		},
+		{
+			name:  "repository web URL with base path",
+			input: "https://example.com/github/OWNER/REPO",
+			opts:  ParseOptions{BasePaths: map[string]string{"example.com": "/github/"}},
+			wantRef: Reference{
+				Repository: Repository{Host: "example.com", Owner: "OWNER", Name: "REPO"},
+			},
+		},
+		{
+			name:    "web URL without base path",
+			input:   "https://example.com/OWNER/REPO/pull/1",
+			opts:    ParseOptions{BasePaths: map[string]string{"example.com": "/github"}},
+			wantErr: "invalid path: /OWNER/REPO/pull/1: path outside of base path",
+		},
+		{
+			name:  "web URL with port",
+			input: "https://example.com:8443/OWNER/REPO/blob/main/README.md",
+			opts:  ParseOptions{KeepPort: true},
+			wantRef: Reference{
+				Repository: Repository{Host: "example.com:8443", Owner: "OWNER", Name: "REPO"},
+				Kind:       ReferenceBlob,
+				Ref:        "main",
+				Path:       "README.md",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseReferenceWithOptions(tt.input, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRef, ref)
+		})
+	}
+}
@@ -3,14 +3,12 @@
 package repository
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/cli/go-gh/v2/internal/git"
 	"github.com/cli/go-gh/v2/pkg/auth"
-	"github.com/cli/go-gh/v2/pkg/ssh"
 )
 
 // Repository holds information representing a GitHub repository.
@@ -20,66 +18,79 @@ type Repository struct {
 	Owner string
 }
 
+// ParseOptions holds optional configuration for ParseWithOptions.
+type ParseOptions struct {
+	// Host is used when the format does not specify a host.
+	// Default is the host determined from the config.
+	Host string
+
+	// SkipValidation disables the checks of the owner and repository name
+	// against the GitHub naming rules, and keeps a ".git" suffix of the
+	// repository name in the "[HOST/]OWNER/REPO" format.
+	SkipValidation bool
+
+	// BasePaths maps hostnames to the path that GitHub Enterprise Server is served
+	// under on that host, such as "/github" for https://example.com/github/OWNER/REPO.
+	// It applies to the URL format only.
+	BasePaths map[string]string
+
+	// KeepPort keeps ports other than the default port of the scheme of a URL in
+	// the host, such as "example.com:8443". Default is to strip ports.
+	KeepPort bool
+
+	// AllowTrailingPath accepts URLs with path segments after the repository name,
+	// such as web URLs of files or pull requests. Default is to reject them.
+	AllowTrailingPath bool
+
+	// RewriteURLs rewrites URLs with the url.<base>.insteadOf rules of the git
+	// configuration before they are parsed, so that shortcuts such as "gh:OWNER/REPO"
+	// are recognized. The rules are read by running git in Dir.
+	// Default is to parse URLs as they are.
+	RewriteURLs bool
+
+	// Dir is the directory whose git configuration is read for RewriteURLs.
+	// Default is the current directory.
+	Dir string
+}
+
 // Parse extracts the repository information from the following
 // string formats: "OWNER/REPO", "HOST/OWNER/REPO", and a full URL.
 // If the format does not specify a host, use the config to determine a host.
+// The owner and repository name are validated with ValidateOwner and ValidateName.
 func Parse(s string) (Repository, error) {
-	var r Repository
-
-	if git.IsURL(s) {
-		u, err := git.ParseURL(s)
-		if err != nil {
-			return r, err
-		}
-
-		host, owner, name, err := git.RepoInfoFromURL(u)
-		if err != nil {
-			return r, err
-		}
-
-		r.Host = host
-		r.Name = name
-		r.Owner = owner
-
-		return r, nil
-	}
-
-	parts := strings.SplitN(s, "/", 4)
-	for _, p := range parts {
-		if len(p) == 0 {
-			return r, fmt.Errorf(`expected the "[HOST/]OWNER/REPO" format, got %q`, s)
-		}
-	}
-
-	switch len(parts) {
-	case 3:
-		r.Host = parts[0]
-		r.Owner = parts[1]
-		r.Name = parts[2]
-		return r, nil
-	case 2:
-		r.Host, _ = auth.DefaultHost()
-		r.Owner = parts[0]
-		r.Name = parts[1]
-		return r, nil
-	default:
-		return r, fmt.Errorf(`expected the "[HOST/]OWNER/REPO" format, got %q`, s)
-	}
+	return ParseWithOptions(s, ParseOptions{})
 }
 
 // Parse extracts the repository information from the following
 // string formats: "OWNER/REPO", "HOST/OWNER/REPO", and a full URL.
 // If the format does not specify a host, use the host provided.
+// The owner and repository name are validated with ValidateOwner and ValidateName.
 func ParseWithHost(s, host string) (Repository, error) {
+	return ParseWithOptions(s, ParseOptions{Host: host})
+}
+
+// ParseWithOptions extracts the repository information from the following
+// string formats: "OWNER/REPO", "HOST/OWNER/REPO", and a full URL.
+// See ParseOptions for the available configuration.
+func ParseWithOptions(s string, opts ParseOptions) (Repository, error) {
 	var r Repository
 
+	s, err := rewriteURL(s, opts)
+	if err != nil {
+		return r, err
+	}
+
 	if git.IsURL(s) {
 		u, err := git.ParseURL(s)
 		if err != nil {
 			return r, err
 		}
 
-		host, owner, name, err := git.RepoInfoFromURL(u)
+		host, owner, name, err := git.RepoInfoFromURLWithOptions(u, git.RepoURLOptions{
+			BasePaths:         opts.BasePaths,
+			KeepPort:          opts.KeepPort,
+			AllowTrailingPath: opts.AllowTrailingPath,
+		})
 		if err != nil {
 			return r, err
 		}
@@ -88,7 +99,7 @@ func ParseWithHost(s, host string) (Repository, error) {
 		r.Owner = owner
 		r.Name = name
 
-		return r, nil
+		return r, validate(r, opts)
 	}
 
 	parts := strings.SplitN(s, "/", 4)
@@ -103,20 +114,56 @@ func ParseWithHost(s, host string) (Repository, error) {
 		r.Host = parts[0]
 		r.Owner = parts[1]
 		r.Name = parts[2]
-		return r, nil
 	case 2:
-		r.Host = host
+		r.Host = opts.Host
+		if r.Host == "" {
+			r.Host, _ = auth.DefaultHost()
+		}
 		r.Owner = parts[0]
 		r.Name = parts[1]
-		return r, nil
 	default:
 		return r, fmt.Errorf(`expected the "[HOST/]OWNER/REPO" format, got %q`, s)
 	}
+
+	if !opts.SkipValidation {
+		r.Name = strings.TrimSuffix(r.Name, ".git")
+	}
+	return r, validate(r, opts)
+}
+
+// rewriteURL applies the URL rewrite rules to s if opts.RewriteURLs is set.
+func rewriteURL(s string, opts ParseOptions) (string, error) {
+	if !opts.RewriteURLs || !(git.IsURL(s) || strings.Contains(s, ":")) {
+		return s, nil
+	}
+	rewrites, err := git.ReadURLRewrites(opts.Dir)
+	if err != nil {
+		return s, err
+	}
+	return rewrites.Rewrite(s), nil
+}
+
+func validate(r Repository, opts ParseOptions) error {
+	if opts.SkipValidation {
+		return nil
+	}
+	if err := ValidateOwner(r.Owner); err != nil {
+		return err
+	}
+	return ValidateName(r.Name)
 }
 
 // Current uses git remotes to determine the GitHub repository
-// the current directory is tracking.
+// the current directory is tracking. See CurrentNetwork for determining
+// the base and head repositories of pull requests.
 func Current() (Repository, error) {
+	return CurrentInDir("")
+}
+
+// CurrentInDir uses git remotes to determine the GitHub repository the specified
+// directory is tracking. The directory can be anywhere within a working tree, or
+// a bare repository. As with Current, the GH_REPO environment variable takes precedence.
+func CurrentInDir(dir string) (Repository, error) {
 	var r Repository
 
 	override := os.Getenv("GH_REPO")
@@ -124,32 +171,12 @@ func Current() (Repository, error) {
 		return Parse(override)
 	}
 
-	remotes, err := git.Remotes()
+	remotes, err := currentRemotes(dir)
 	if err != nil {
 		return r, err
 	}
-	if len(remotes) == 0 {
-		return r, errors.New("unable to determine current repository, no git remotes configured for this repository")
-	}
-
-	translator := ssh.NewTranslator()
-	for _, r := range remotes {
-		if r.FetchURL != nil {
-			r.FetchURL = translator.Translate(r.FetchURL)
-		}
-		if r.PushURL != nil {
-			r.PushURL = translator.Translate(r.PushURL)
-		}
-	}
-
-	hosts := auth.KnownHosts()
-
-	filteredRemotes := remotes.FilterByHosts(hosts)
-	if len(filteredRemotes) == 0 {
-		return r, errors.New("unable to determine current repository, none of the git remotes configured for this repository point to a known GitHub host")
-	}
 
-	rem := filteredRemotes[0]
+	rem := remotes[0]
 	r.Host = rem.Host
 	r.Owner = rem.Owner
 	r.Name = rem.Repo
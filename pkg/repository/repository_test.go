@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"os"
 	"testing"
 
 	"github.com/cli/go-gh/v2/pkg/config"
@@ -114,6 +115,46 @@ hosts:
 	assert.Equal(t, "REPO", r.Name)
 }
 
+func TestParseWithOptions_rewriteURLs(t *testing.T) {
+	stubConfig(t, "")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_COUNT", "2")
+	t.Setenv("GIT_CONFIG_KEY_0", "url.https://github.com/.insteadOf")
+	t.Setenv("GIT_CONFIG_VALUE_0", "gh:")
+	t.Setenv("GIT_CONFIG_KEY_1", "url.git@ghe.example.com:.insteadOf")
+	t.Setenv("GIT_CONFIG_VALUE_1", "https://ghe.internal/")
+
+	opts := ParseOptions{RewriteURLs: true, Dir: t.TempDir()}
+
+	r, err := ParseWithOptions("gh:OWNER/REPO", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"}, r)
+
+	r, err = ParseWithOptions("https://ghe.internal/OWNER/REPO.git", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Repository{Host: "ghe.example.com", Owner: "OWNER", Name: "REPO"}, r)
+
+	r, err = ParseWithOptions("OWNER/REPO", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"}, r)
+
+	ref, err := ParseReferenceWithOptions("gh:OWNER/REPO/pull/1", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Reference{
+		Repository: Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"},
+		Kind:       ReferencePullRequest,
+		Number:     1,
+	}, ref)
+
+	// URLs are only rewritten on request.
+	_, err = Parse("gh:OWNER/REPO")
+	assert.Error(t, err)
+	r, err = Parse("https://ghe.internal/OWNER/REPO.git")
+	assert.NoError(t, err)
+	assert.Equal(t, Repository{Host: "ghe.internal", Owner: "OWNER", Name: "REPO"}, r)
+}
+
 func TestParseWithHost(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// Browser represents a web browser that can open URLs, such as *browser.Browser.
+type Browser interface {
+	Browse(url string) error
+}
+
+// URL returns the web URL of the repository home page.
+func (r Repository) URL() string {
+	return fmt.Sprintf("%s%s/%s", auth.NewHost(r.Host).WebURL(), escapePath(r.Owner), escapePath(r.Name))
+}
+
+// WebURL returns the web URL of the specified path within the repository,
+// for example "pulls" or "wiki/Home". An empty path returns the repository home page.
+func (r Repository) WebURL(p string) string {
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return r.URL()
+	}
+	return r.URL() + "/" + p
+}
+
+// FileURL returns the web URL of the file at path in the specified ref, which may be a
+// branch, tag, or commit SHA. If startLine is greater than zero the lines from startLine
+// to endLine are highlighted; an endLine of zero or less highlights only startLine.
+func (r Repository) FileURL(ref, filePath string, startLine, endLine int) string {
+	u := r.WebURL(fmt.Sprintf("blob/%s/%s", escapePath(ref), escapePath(strings.TrimPrefix(filePath, "/"))))
+	if startLine <= 0 {
+		return u
+	}
+	// Line anchors only work on the source view of rendered files such as Markdown.
+	if isRenderedFile(filePath) {
+		u += "?plain=1"
+	}
+	if endLine <= startLine {
+		return fmt.Sprintf("%s#L%d", u, startLine)
+	}
+	return fmt.Sprintf("%s#L%d-L%d", u, startLine, endLine)
+}
+
+// IssueURL returns the web URL of the issue with the specified number.
+func (r Repository) IssueURL(number int) string {
+	return r.WebURL(fmt.Sprintf("issues/%d", number))
+}
+
+// PullRequestURL returns the web URL of the pull request with the specified number.
+func (r Repository) PullRequestURL(number int) string {
+	return r.WebURL(fmt.Sprintf("pull/%d", number))
+}
+
+// CompareURL returns the web URL comparing head against base. If base is empty
+// the comparison is against the default branch of the repository. A head
+// in another fork of the repository can be specified as "OWNER:BRANCH".
+func (r Repository) CompareURL(base, head string) string {
+	if base == "" {
+		return r.WebURL("compare/" + escapePath(head))
+	}
+	return r.WebURL(fmt.Sprintf("compare/%s...%s", escapePath(base), escapePath(head)))
+}
+
+// ReleasesURL returns the web URL listing the releases of the repository.
+func (r Repository) ReleasesURL() string {
+	return r.WebURL("releases")
+}
+
+// ReleaseURL returns the web URL of the release for the specified tag.
+func (r Repository) ReleaseURL(tag string) string {
+	return r.WebURL("releases/tag/" + escapePath(tag))
+}
+
+// ActionsRunURL returns the web URL of the GitHub Actions workflow run with the specified ID.
+func (r Repository) ActionsRunURL(runID int64) string {
+	return r.WebURL(fmt.Sprintf("actions/runs/%d", runID))
+}
+
+// SettingsURL returns the web URL of the repository settings.
+func (r Repository) SettingsURL() string {
+	return r.WebURL("settings")
+}
+
+// Browse opens the web URL of the specified path within the repository
+// in the browser. See WebURL for the format of the path.
+func (r Repository) Browse(b Browser, p string) error {
+	return b.Browse(r.WebURL(p))
+}
+
+// escapePath escapes each segment of a slash separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func isRenderedFile(filePath string) bool {
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".md", ".markdown", ".mdown", ".mkdn", ".rst", ".adoc", ".asciidoc", ".org", ".textile", ".rdoc", ".creole", ".mediawiki", ".wiki", ".pod", ".ipynb":
+		return true
+	}
+	return false
+}
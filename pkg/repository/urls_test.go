@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		url  func(Repository) string
+		want string
+	}{
+		{
+			name: "home",
+			url:  func(r Repository) string { return r.URL() },
+			want: "/OWNER/REPO",
+		},
+		{
+			name: "web path",
+			url:  func(r Repository) string { return r.WebURL("/pulls") },
+			want: "/OWNER/REPO/pulls",
+		},
+		{
+			name: "file",
+			url:  func(r Repository) string { return r.FileURL("feature/one", "cmd/main.go", 0, 0) },
+			want: "/OWNER/REPO/blob/feature/one/cmd/main.go",
+		},
+		{
+			name: "file with escaped characters",
+			url:  func(r Repository) string { return r.FileURL("v1.0", "docs/a file#1.txt", 0, 0) },
+			want: "/OWNER/REPO/blob/v1.0/docs/a%20file%231.txt",
+		},
+		{
+			name: "file with line",
+			url:  func(r Repository) string { return r.FileURL("main", "main.go", 10, 0) },
+			want: "/OWNER/REPO/blob/main/main.go#L10",
+		},
+		{
+			name: "file with line range",
+			url:  func(r Repository) string { return r.FileURL("main", "main.go", 10, 20) },
+			want: "/OWNER/REPO/blob/main/main.go#L10-L20",
+		},
+		{
+			name: "rendered file with line range",
+			url:  func(r Repository) string { return r.FileURL("main", "README.md", 1, 3) },
+			want: "/OWNER/REPO/blob/main/README.md?plain=1#L1-L3",
+		},
+		{
+			name: "issue",
+			url:  func(r Repository) string { return r.IssueURL(12) },
+			want: "/OWNER/REPO/issues/12",
+		},
+		{
+			name: "pull request",
+			url:  func(r Repository) string { return r.PullRequestURL(34) },
+			want: "/OWNER/REPO/pull/34",
+		},
+		{
+			name: "compare",
+			url:  func(r Repository) string { return r.CompareURL("main", "monalisa:feature") },
+			want: "/OWNER/REPO/compare/main...monalisa:feature",
+		},
+		{
+			name: "compare against default branch",
+			url:  func(r Repository) string { return r.CompareURL("", "feature") },
+			want: "/OWNER/REPO/compare/feature",
+		},
+		{
+			name: "releases",
+			url:  func(r Repository) string { return r.ReleasesURL() },
+			want: "/OWNER/REPO/releases",
+		},
+		{
+			name: "release",
+			url:  func(r Repository) string { return r.ReleaseURL("v1.2.3") },
+			want: "/OWNER/REPO/releases/tag/v1.2.3",
+		},
+		{
+			name: "actions run",
+			url:  func(r Repository) string { return r.ActionsRunURL(5678) },
+			want: "/OWNER/REPO/actions/runs/5678",
+		},
+		{
+			name: "settings",
+			url:  func(r Repository) string { return r.SettingsURL() },
+			want: "/OWNER/REPO/settings",
+		},
+	}
+
+	hosts := []struct {
+		host       string
+		wantPrefix string
+	}{
+		{host: "github.com", wantPrefix: "https://github.com"},
+		{host: "api.github.com", wantPrefix: "https://github.com"},
+		{host: "enterprise.com", wantPrefix: "https://enterprise.com"},
+		{host: "tenant.ghe.com", wantPrefix: "https://tenant.ghe.com"},
+		{host: "github.localhost", wantPrefix: "http://github.localhost"},
+	}
+
+	for _, h := range hosts {
+		for _, tt := range tests {
+			t.Run(h.host+" "+tt.name, func(t *testing.T) {
+				r := Repository{Host: h.host, Owner: "OWNER", Name: "REPO"}
+				assert.Equal(t, h.wantPrefix+tt.want, tt.url(r))
+			})
+		}
+	}
+}
+
+func TestRepositoryBrowse(t *testing.T) {
+	b := &stubBrowser{}
+	r := Repository{Host: "enterprise.com", Owner: "OWNER", Name: "REPO"}
+	assert.NoError(t, r.Browse(b, "issues/new"))
+	assert.Equal(t, "https://enterprise.com/OWNER/REPO/issues/new", b.url)
+}
+
+type stubBrowser struct {
+	url string
+}
+
+func (b *stubBrowser) Browse(url string) error {
+	b.url = url
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxOwnerLength = 39
+	maxNameLength  = 100
+)
+
+// reservedOwners are top-level paths of the GitHub web interface that can never be
+// account logins. They usually mean a web URL that does not point to a repository
+// was parsed.
+var reservedOwners = map[string]bool{
+	"about":         true,
+	"apps":          true,
+	"explore":       true,
+	"features":      true,
+	"issues":        true,
+	"login":         true,
+	"logout":        true,
+	"marketplace":   true,
+	"new":           true,
+	"notifications": true,
+	"organizations": true,
+	"orgs":          true,
+	"pulls":         true,
+	"search":        true,
+	"settings":      true,
+	"sponsors":      true,
+	"topics":        true,
+}
+
+// InvalidOwnerError is returned when a repository owner is not a valid GitHub login.
+type InvalidOwnerError struct {
+	Owner  string
+	Reason string
+}
+
+func (e *InvalidOwnerError) Error() string {
+	return fmt.Sprintf("invalid repository owner %q: %s", e.Owner, e.Reason)
+}
+
+// InvalidNameError is returned when a repository name is not valid on GitHub.
+type InvalidNameError struct {
+	Name   string
+	Reason string
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("invalid repository name %q: %s", e.Name, e.Reason)
+}
+
+// ValidateOwner checks that owner follows the GitHub rules for user and organization
+// logins. Underscores are allowed for Enterprise Managed Users. If the owner is
+// invalid the returned error is an *InvalidOwnerError.
+func ValidateOwner(owner string) error {
+	reason := ""
+	switch {
+	case owner == "":
+		reason = "must not be empty"
+	case len(owner) > maxOwnerLength:
+		reason = fmt.Sprintf("must be at most %d characters long", maxOwnerLength)
+	case strings.IndexFunc(owner, func(r rune) bool { return !isAlphanumeric(r) && r != '-' && r != '_' }) >= 0:
+		reason = "may only contain alphanumeric characters, hyphens, and underscores"
+	case !isAlphanumeric(rune(owner[0])):
+		reason = "must begin with an alphanumeric character"
+	case reservedOwners[strings.ToLower(owner)]:
+		reason = "is a reserved name"
+	default:
+		return nil
+	}
+	return &InvalidOwnerError{Owner: owner, Reason: reason}
+}
+
+// ValidateName checks that name follows the GitHub rules for repository names.
+// If the name is invalid the returned error is an *InvalidNameError.
+func ValidateName(name string) error {
+	reason := ""
+	switch {
+	case name == "":
+		reason = "must not be empty"
+	case len(name) > maxNameLength:
+		reason = fmt.Sprintf("must be at most %d characters long", maxNameLength)
+	case strings.IndexFunc(name, func(r rune) bool { return !isAlphanumeric(r) && r != '-' && r != '_' && r != '.' }) >= 0:
+		reason = "may only contain alphanumeric characters, hyphens, underscores, and periods"
+	case name == "." || name == "..":
+		reason = "is a reserved name"
+	default:
+		return nil
+	}
+	return &InvalidNameError{Name: name, Reason: reason}
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
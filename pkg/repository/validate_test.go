@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOwner(t *testing.T) {
+	tests := []struct {
+		name    string
+		owner   string
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			owner: "mona-lisa",
+		},
+		{
+			name:  "managed user",
+			owner: "monalisa_acme",
+		},
+		{
+			name:    "empty",
+			owner:   "",
+			wantErr: `invalid repository owner "": must not be empty`,
+		},
+		{
+			name:    "too long",
+			owner:   strings.Repeat("a", 40),
+			wantErr: `invalid repository owner "` + strings.Repeat("a", 40) + `": must be at most 39 characters long`,
+		},
+		{
+			name:    "invalid characters",
+			owner:   "foo bar",
+			wantErr: `invalid repository owner "foo bar": may only contain alphanumeric characters, hyphens, and underscores`,
+		},
+		{
+			name:    "leading hyphen",
+			owner:   "-foo",
+			wantErr: `invalid repository owner "-foo": must begin with an alphanumeric character`,
+		},
+		{
+			name:    "reserved",
+			owner:   "Settings",
+			wantErr: `invalid repository owner "Settings": is a reserved name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOwner(tt.owner)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var ownerErr *InvalidOwnerError
+			assert.ErrorAs(t, err, &ownerErr)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoName string
+		wantErr  string
+	}{
+		{
+			name:     "valid",
+			repoName: "go-gh_v2.0",
+		},
+		{
+			name:     "dotfile",
+			repoName: ".github",
+		},
+		{
+			name:     "empty",
+			repoName: "",
+			wantErr:  `invalid repository name "": must not be empty`,
+		},
+		{
+			name:     "too long",
+			repoName: strings.Repeat("a", 101),
+			wantErr:  `invalid repository name "` + strings.Repeat("a", 101) + `": must be at most 100 characters long`,
+		},
+		{
+			name:     "invalid characters",
+			repoName: "baz?",
+			wantErr:  `invalid repository name "baz?": may only contain alphanumeric characters, hyphens, underscores, and periods`,
+		},
+		{
+			name:     "reserved",
+			repoName: "..",
+			wantErr:  `invalid repository name "..": is a reserved name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.repoName)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var nameErr *InvalidNameError
+			assert.ErrorAs(t, err, &nameErr)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     ParseOptions
+		wantRepo Repository
+		wantErr  string
+	}{
+		{
+			name:     "strips .git suffix",
+			input:    "OWNER/REPO.git",
+			opts:     ParseOptions{Host: "github.com"},
+			wantRepo: Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"},
+		},
+		{
+			name:    "invalid owner",
+			input:   "foo bar/baz",
+			opts:    ParseOptions{Host: "github.com"},
+			wantErr: `invalid repository owner "foo bar": may only contain alphanumeric characters, hyphens, and underscores`,
+		},
+		{
+			name:    "invalid name",
+			input:   "example.com/foo/baz?",
+			wantErr: `invalid repository name "baz?": may only contain alphanumeric characters, hyphens, underscores, and periods`,
+		},
+		{
+			name:    "invalid name in URL",
+			input:   "https://github.com/foo/..",
+			wantErr: `invalid repository name "..": is a reserved name`,
+		},
+		{
+			name:     "skip validation",
+			input:    "foo bar/baz?.git",
+			opts:     ParseOptions{Host: "github.com", SkipValidation: true},
+			wantRepo: Repository{Host: "github.com", Owner: "foo bar", Name: "baz?.git"},
+		},
+		{
+			name:     "URL under base path",
+			input:    "https://example.com/github/OWNER/REPO.git",
+			opts:     ParseOptions{BasePaths: map[string]string{"example.com": "/github"}},
+			wantRepo: Repository{Host: "example.com", Owner: "OWNER", Name: "REPO"},
+		},
+		{
+			name:    "URL outside of base path",
+			input:   "https://example.com/OWNER/REPO.git",
+			opts:    ParseOptions{BasePaths: map[string]string{"example.com": "/github"}},
+			wantErr: "invalid path: /OWNER/REPO.git: path outside of base path",
+		},
+		{
+			name:     "URL with port",
+			input:    "https://example.com:8443/OWNER/REPO",
+			opts:     ParseOptions{KeepPort: true},
+			wantRepo: Repository{Host: "example.com:8443", Owner: "OWNER", Name: "REPO"},
+		},
+		{
+			name:     "URL with trailing path",
+			input:    "https://github.com/OWNER/REPO/pull/123",
+			opts:     ParseOptions{AllowTrailingPath: true},
+			wantRepo: Repository{Host: "github.com", Owner: "OWNER", Name: "REPO"},
+		},
+		{
+			name:    "URL with trailing path by default",
+			input:   "https://github.com/OWNER/REPO/pull/123",
+			wantErr: "invalid path: /OWNER/REPO/pull/123: path after repository name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseWithOptions(tt.input, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRepo, r)
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package ssh
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth matches the limit on nested Include directives of OpenSSH.
+const maxIncludeDepth = 16
+
+// Config is an OpenSSH client configuration, as described in ssh_config(5).
+//
+// Only the subset needed to resolve host aliases is interpreted: Host and Match
+// blocks, Include directives, and the %h token of Hostname. Match blocks with
+// criteria other than "all", "host" and "originalhost" never apply, as they can
+// not be evaluated without running ssh.
+type Config struct {
+	directives []directive
+}
+
+type directive struct {
+	keyword string
+	args    []string
+
+	// children are the directives of the files matched by an Include directive.
+	children []directive
+}
+
+// LoadConfig reads and parses the specified configuration files in order, so that
+// values in earlier files take precedence. Missing files are skipped. Relative paths
+// of Include directives are resolved against the directory of the file passed to
+// LoadConfig, which is ~/.ssh for the user and /etc/ssh for the system configuration.
+func LoadConfig(paths ...string) (*Config, error) {
+	c := &Config{}
+	for _, path := range paths {
+		directives, err := parseConfigFile(path, filepath.Dir(path), 0)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		c.directives = append(c.directives, directives...)
+	}
+	return c, nil
+}
+
+// Get returns the first value of keyword that applies to host, or an empty
+// string if it is not set. Keywords are case-insensitive.
+func (c *Config) Get(host, keyword string) string {
+	values := c.GetAll(host, keyword)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// GetAll returns all values of keyword that apply to host in order, which is
+// useful for keywords that may be specified multiple times, such as IdentityFile.
+func (c *Config) GetAll(host, keyword string) []string {
+	return c.evaluate(host)[strings.ToLower(keyword)]
+}
+
+// evaluate returns the values of all keywords that apply to host.
+func (c *Config) evaluate(host string) map[string][]string {
+	values := map[string][]string{}
+	applyDirectives(c.directives, host, values)
+	return values
+}
+
+func applyDirectives(directives []directive, host string, values map[string][]string) {
+	active := true
+	for _, d := range directives {
+		switch d.keyword {
+		case "host":
+			active = matchHost(host, d.args)
+		case "match":
+			active = matchCriteria(host, d.args, values)
+		case "include":
+			// Included files start out active and do not change the state of the
+			// including file, regardless of the Host and Match blocks they contain.
+			if active {
+				applyDirectives(d.children, host, values)
+			}
+		default:
+			if active {
+				value := strings.Join(d.args, " ")
+				if d.keyword == "hostname" {
+					value = expandHostname(value, host)
+				}
+				values[d.keyword] = append(values[d.keyword], value)
+			}
+		}
+	}
+}
+
+// matchHost reports whether host matches a list of Host patterns. A matching
+// negated pattern excludes the host even if other patterns match.
+func matchHost(host string, patterns []string) bool {
+	matched := false
+	for _, p := range patterns {
+		if negated := strings.HasPrefix(p, "!"); negated {
+			if matchPattern(host, p[1:]) {
+				return false
+			}
+		} else if matchPattern(host, p) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchCriteria reports whether all criteria of a Match directive apply to host.
+// The host criterion is evaluated against the Hostname set so far, if any.
+func matchCriteria(host string, args []string, values map[string][]string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	for i := 0; i < len(args); i++ {
+		criterion := strings.ToLower(args[i])
+		negated := strings.HasPrefix(criterion, "!")
+		criterion = strings.TrimPrefix(criterion, "!")
+
+		var matched bool
+		switch criterion {
+		case "all":
+			matched = true
+		case "host", "originalhost":
+			if i+1 >= len(args) {
+				return false
+			}
+			i++
+			target := host
+			if criterion == "host" && len(values["hostname"]) > 0 {
+				target = values["hostname"][0]
+			}
+			matched = matchHost(target, strings.Split(args[i], ","))
+		default:
+			return false
+		}
+		if matched == negated {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPattern reports whether s matches pattern case-insensitively, where '*'
+// matches any sequence of characters and '?' matches a single character.
+func matchPattern(s, pattern string) bool {
+	s, pattern = strings.ToLower(s), strings.ToLower(pattern)
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchPattern(s[i:], pattern) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		s, pattern = s[1:], pattern[1:]
+	}
+	return s == ""
+}
+
+// expandHostname expands the %h and %% tokens of a Hostname value.
+func expandHostname(value, host string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+1 < len(value) {
+			switch value[i+1] {
+			case 'h':
+				b.WriteString(host)
+				i++
+				continue
+			case '%':
+				b.WriteByte('%')
+				i++
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+func parseConfigFile(path, includeDir string, depth int) ([]directive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseConfig(f, path, includeDir, depth)
+}
+
+func parseConfig(r io.Reader, name, includeDir string, depth int) ([]directive, error) {
+	var directives []directive
+	s := bufio.NewScanner(r)
+	lineNum := 0
+	for s.Scan() {
+		lineNum++
+		keyword, args, err := parseConfigLine(s.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", name, lineNum, err)
+		}
+		if keyword == "" {
+			continue
+		}
+		d := directive{keyword: keyword, args: args}
+		if keyword == "include" {
+			if depth >= maxIncludeDepth {
+				return nil, fmt.Errorf("%s line %d: too many nested includes", name, lineNum)
+			}
+			for _, pattern := range args {
+				children, err := parseInclude(pattern, includeDir, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				d.children = append(d.children, children...)
+			}
+		}
+		directives = append(directives, d)
+	}
+	return directives, s.Err()
+}
+
+func parseInclude(pattern, includeDir string, depth int) ([]directive, error) {
+	if strings.HasPrefix(pattern, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		pattern = filepath.Join(home, pattern[2:])
+	} else if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(includeDir, pattern)
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var directives []directive
+	for _, path := range paths {
+		children, err := parseConfigFile(path, includeDir, depth)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, children...)
+	}
+	return directives, nil
+}
+
+// parseConfigLine returns the lowercased keyword and the arguments of a configuration
+// line, which has the form "Keyword arguments" or "Keyword=arguments". The keyword is
+// empty for blank lines and comments.
+func parseConfigLine(line string) (string, []string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil, nil
+	}
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil, nil
+	}
+	keyword := strings.ToLower(line[:end])
+	rest := strings.TrimLeft(line[end:], " \t")
+	if strings.HasPrefix(rest, "=") {
+		rest = strings.TrimLeft(rest[1:], " \t")
+	}
+	args, err := splitArgs(rest)
+	return keyword, args, err
+}
+
+// splitArgs splits s at whitespace, keeping double-quoted arguments together.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" || strings.HasPrefix(s, "#") {
+			return args, nil
+		}
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return nil, errors.New("unterminated quoted string")
+			}
+			args = append(args, s[1:end+1])
+			s = s[end+2:]
+			continue
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		args = append(args, s[:end])
+		s = s[end:]
+	}
+}
+
+// defaultConfigPaths returns the paths of the user and system configuration files.
+func defaultConfigPaths() []string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".ssh", "config"))
+	}
+	return append(paths, filepath.Join(string(filepath.Separator), "etc", "ssh", "ssh_config"))
+}
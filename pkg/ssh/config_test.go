@@ -0,0 +1,334 @@
+package ssh
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigGet(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		host   string
+		want   string
+	}{
+		{
+			name: "exact host",
+			config: heredoc.Doc(`
+				Host work
+					Hostname github.com
+			`),
+			host: "work",
+			want: "github.com",
+		},
+		{
+			name: "wildcard host with equals sign",
+			config: heredoc.Doc(`
+				Host github-*
+					HostName=github.com
+			`),
+			host: "github-foo",
+			want: "github.com",
+		},
+		{
+			name: "question mark pattern",
+			config: heredoc.Doc(`
+				Host gh?
+					Hostname github.com
+			`),
+			host: "gh1",
+			want: "github.com",
+		},
+		{
+			name: "no matching host",
+			config: heredoc.Doc(`
+				Host github-*
+					Hostname github.com
+			`),
+			host: "gitlab",
+			want: "",
+		},
+		{
+			name: "first value wins",
+			config: heredoc.Doc(`
+				Host work
+					Hostname first.example.com
+				Host *
+					Hostname second.example.com
+			`),
+			host: "work",
+			want: "first.example.com",
+		},
+		{
+			name: "negated pattern",
+			config: heredoc.Doc(`
+				Host *.example.com !internal.example.com
+					Hostname proxy.example.com
+			`),
+			host: "internal.example.com",
+			want: "",
+		},
+		{
+			name: "case-insensitive keywords and patterns",
+			config: heredoc.Doc(`
+				HOST Work
+					HOSTNAME github.com
+			`),
+			host: "work",
+			want: "github.com",
+		},
+		{
+			name: "percent-h token",
+			config: heredoc.Doc(`
+				Host *.corp
+					Hostname %h.example.com
+			`),
+			host: "ghe.corp",
+			want: "ghe.corp.example.com",
+		},
+		{
+			name: "global options before the first Host block",
+			config: heredoc.Doc(`
+				Hostname global.example.com
+				Host work
+					Hostname github.com
+			`),
+			host: "work",
+			want: "global.example.com",
+		},
+		{
+			name: "match host uses the hostname set so far",
+			config: heredoc.Doc(`
+				Host work
+					Hostname github.com
+				Match host github.com
+					Port 443
+			`),
+			host: "work",
+			want: "github.com",
+		},
+		{
+			name: "match originalhost",
+			config: heredoc.Doc(`
+				Match originalhost work,home
+					Hostname github.com
+			`),
+			host: "home",
+			want: "github.com",
+		},
+		{
+			name: "negated match criterion",
+			config: heredoc.Doc(`
+				Match !host work
+					Hostname github.com
+			`),
+			host: "work",
+			want: "",
+		},
+		{
+			name: "unsupported match criterion never applies",
+			config: heredoc.Doc(`
+				Match exec "true"
+					Hostname github.com
+			`),
+			host: "work",
+			want: "",
+		},
+		{
+			name: "quoted arguments and comments",
+			config: heredoc.Doc(`
+				# comment
+				Host "work" # trailing comment
+					Hostname github.com
+			`),
+			host: "work",
+			want: "github.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), "config", tt.config)
+			cfg, err := LoadConfig(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg.Get(tt.host, "Hostname"))
+		})
+	}
+}
+
+func TestConfigMatchHostPort(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config", heredoc.Doc(`
+		Host work
+			Hostname github.com
+		Match host github.com
+			Port 443
+			IdentityFile ~/.ssh/work
+		Host *
+			IdentityFile ~/.ssh/id_ed25519
+	`))
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "443", cfg.Get("work", "port"))
+	assert.Equal(t, []string{"~/.ssh/work", "~/.ssh/id_ed25519"}, cfg.GetAll("work", "IdentityFile"))
+	assert.Equal(t, "", cfg.Get("other", "port"))
+}
+
+func TestConfigInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.d/10-work", heredoc.Doc(`
+		Host work
+			Hostname github.com
+		Host *
+			User git
+	`))
+	writeConfig(t, dir, "config.d/20-home", heredoc.Doc(`
+		Host home
+			Hostname home.example.com
+	`))
+	writeConfig(t, dir, "scoped", heredoc.Doc(`
+		Port 2222
+	`))
+	path := writeConfig(t, dir, "config", heredoc.Doc(`
+		Include config.d/*
+		Host scoped
+			Include scoped
+			User scoped
+	`))
+	cfg, err := LoadConfig(path, filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "github.com", cfg.Get("work", "hostname"))
+	assert.Equal(t, "home.example.com", cfg.Get("home", "hostname"))
+	assert.Equal(t, "git", cfg.Get("work", "user"))
+	assert.Equal(t, "2222", cfg.Get("scoped", "port"))
+	assert.Equal(t, "", cfg.Get("work", "port"))
+	// Host blocks in included files do not affect the including file.
+	assert.Equal(t, "git", cfg.Get("scoped", "user"))
+}
+
+func TestConfigIncludeRecursion(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "config", "Include config\n")
+	_, err := LoadConfig(path)
+	assert.EqualError(t, err, path+" line 1: too many nested includes")
+}
+
+func TestConfigParseError(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config", "Host \"work\n")
+	_, err := LoadConfig(path)
+	assert.EqualError(t, err, path+" line 1: unterminated quoted string")
+}
+
+func TestConfigTranslator(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "config", heredoc.Doc(`
+		Host github-*
+			Hostname github.com
+		Host gh-ssh
+			Hostname ssh.github.com
+		Host ghes
+			Hostname ssh.ghe.example.com
+			Port 443
+		Host ghes-port
+			Hostname ghe.example.com
+			Port 2222
+	`))
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{
+			name: "translate SSH URL",
+			arg:  "ssh://git@github-foo/owner/repo.git",
+			want: "ssh://git@github.com/owner/repo.git",
+		},
+		{
+			name: "does not translate HTTPS URL",
+			arg:  "https://github-foo/owner/repo.git",
+			want: "https://github-foo/owner/repo.git",
+		},
+		{
+			name: "treats ssh.github.com as github.com",
+			arg:  "ssh://git@gh-ssh/owner/repo.git",
+			want: "ssh://git@github.com/owner/repo.git",
+		},
+		{
+			name: "maps GHES SSH over HTTPS port to web host",
+			arg:  "ssh://git@ghes/owner/repo.git",
+			want: "ssh://git@ghe.example.com/owner/repo.git",
+		},
+		{
+			name: "keeps non-default port",
+			arg:  "ssh://git@ghes-port/owner/repo.git",
+			want: "ssh://git@ghe.example.com:2222/owner/repo.git",
+		},
+		{
+			name: "port of URL takes precedence",
+			arg:  "ssh://git@ghes-port:2200/owner/repo.git",
+			want: "ssh://git@ghe.example.com:2200/owner/repo.git",
+		},
+		{
+			name: "unknown host is unchanged",
+			arg:  "ssh://git@example.com/owner/repo.git",
+			want: "ssh://git@example.com/owner/repo.git",
+		},
+	}
+	tr := NewConfigTranslator(path)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.arg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tr.Translate(u).String())
+		})
+	}
+}
+
+func TestTranslator_configFallback(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config", heredoc.Doc(`
+		Host work
+			Hostname github.com
+	`))
+	tr := &Translator{
+		configPaths: []string{path},
+		lookPath: func(string) (string, error) {
+			return "", os.ErrNotExist
+		},
+	}
+	u, err := url.Parse("ssh://git@work/owner/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, "ssh://git@github.com/owner/repo.git", tr.Translate(u).String())
+}
+
+func TestConfigTranslator_Resolve(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config", heredoc.Doc(`
+		Host work
+			Hostname ssh.tenant.ghe.com
+			User git
+			IdentityFile ~/.ssh/work
+			ProxyCommand none
+	`))
+	res, err := NewConfigTranslator(path).Resolve("work")
+	require.NoError(t, err)
+	assert.Equal(t, Resolution{
+		Hostname:      "ssh.tenant.ghe.com",
+		Port:          22,
+		User:          "git",
+		IdentityFiles: []string{"~/.ssh/work"},
+	}, res)
+	assert.Equal(t, "tenant.ghe.com", res.WebHost())
+}
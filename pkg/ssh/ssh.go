@@ -3,48 +3,124 @@ package ssh
 
 import (
 	"bufio"
+	"net"
 	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/cli/safeexec"
 )
 
+const (
+	defaultPort = 22
+	httpsPort   = 443
+)
+
 type Translator struct {
-	cacheMap   map[string]string
+	cacheMap   map[string]Resolution
 	cacheMu    sync.RWMutex
 	sshPath    string
 	sshPathErr error
 	sshPathMu  sync.Mutex
 
+	// configOnly disables running ssh in favor of parsing configPaths.
+	configOnly  bool
+	configPaths []string
+	config      *Config
+	configErr   error
+	configOnce  sync.Once
+
 	lookPath   func(string) (string, error)
 	newCommand func(string, ...string) *exec.Cmd
 }
 
-// NewTranslator initializes a new Translator instance.
+// NewTranslator initializes a new Translator instance. Aliases are resolved by
+// running "ssh -G", falling back to parsing the OpenSSH configuration files of
+// the user and the system if ssh is not installed.
 func NewTranslator() *Translator {
 	return &Translator{}
 }
 
+// NewConfigTranslator initializes a new Translator instance that resolves aliases
+// by parsing the specified OpenSSH configuration files with LoadConfig instead of
+// running ssh, which is faster but only supports a subset of the configuration.
+// Default is ~/.ssh/config followed by /etc/ssh/ssh_config.
+func NewConfigTranslator(paths ...string) *Translator {
+	return &Translator{configOnly: true, configPaths: paths}
+}
+
+// Resolution is the effective SSH configuration of a host alias.
+type Resolution struct {
+	// Hostname is the real host name to connect to.
+	Hostname string
+
+	// Port is the port to connect to, 22 unless configured otherwise.
+	Port int
+
+	// User is the user to log in as. It is the local user name if not configured
+	// when resolved by ssh, and empty when resolved from the configuration files.
+	User string
+
+	// IdentityFiles are the configured private key files in the order they are tried.
+	IdentityFiles []string
+
+	// HasProxyCommand reports whether connections are made through a ProxyCommand
+	// or ProxyJump, in which case Hostname might not be reachable directly.
+	HasProxyCommand bool
+}
+
+// WebHost returns the host name of the web interface of the GitHub host that
+// Hostname and Port refer to. SSH over the HTTPS port is served by a separate
+// host on github.com, GHE.com and some GHES instances, such as ssh.github.com,
+// ssh.TENANT.ghe.com and ssh.HOST on port 443, which map to github.com,
+// TENANT.ghe.com and HOST respectively. Other host names are returned unchanged.
+func (r Resolution) WebHost() string {
+	host := strings.ToLower(r.Hostname)
+	if !strings.HasPrefix(host, "ssh.") {
+		return r.Hostname
+	}
+	webHost := strings.TrimPrefix(host, "ssh.")
+	if webHost == "github.com" || strings.HasSuffix(webHost, ".ghe.com") || r.Port == httpsPort {
+		return webHost
+	}
+	return r.Hostname
+}
+
 // Translate applies applicable SSH hostname aliases to the specified URL and returns the resulting URL.
+// The port of the alias is added to the URL unless it is the default or the URL has one, and hosts that
+// serve SSH on behalf of a GitHub host are replaced with the web host as described for Resolution.WebHost.
 func (t *Translator) Translate(u *url.URL) *url.URL {
 	if u.Scheme != "ssh" {
 		return u
 	}
-	resolvedHost, err := t.resolve(u.Hostname())
+	res, err := t.Resolve(u.Hostname())
 	if err != nil {
 		return u
 	}
-	if strings.EqualFold(resolvedHost, "ssh.github.com") {
-		resolvedHost = "github.com"
+	port := u.Port()
+	if port == "" && res.Port != 0 && res.Port != defaultPort {
+		port = strconv.Itoa(res.Port)
+	}
+	if p, err := strconv.Atoi(port); err == nil {
+		res.Port = p
+	}
+	host := res.WebHost()
+	if host != res.Hostname {
+		port = ""
 	}
 	newURL, _ := url.Parse(u.String())
-	newURL.Host = resolvedHost
+	newURL.Host = host
+	if port != "" {
+		newURL.Host = net.JoinHostPort(host, port)
+	}
 	return newURL
 }
 
-func (t *Translator) resolve(hostname string) (string, error) {
+// Resolve returns the effective SSH configuration of the specified host alias. If
+// resolution fails the result has the hostname unchanged and the default port.
+func (t *Translator) Resolve(hostname string) (Resolution, error) {
 	t.cacheMu.RLock()
 	cached, cacheFound := t.cacheMap[strings.ToLower(hostname)]
 	t.cacheMu.RUnlock()
@@ -52,6 +128,10 @@ func (t *Translator) resolve(hostname string) (string, error) {
 		return cached, nil
 	}
 
+	if t.configOnly {
+		return t.resolveFromConfig(hostname)
+	}
+
 	var sshPath string
 	t.sshPathMu.Lock()
 	if t.sshPath == "" && t.sshPathErr == nil {
@@ -62,8 +142,8 @@ func (t *Translator) resolve(hostname string) (string, error) {
 		t.sshPath, t.sshPathErr = lookPath("ssh")
 	}
 	if t.sshPathErr != nil {
-		defer t.sshPathMu.Unlock()
-		return t.sshPath, t.sshPathErr
+		t.sshPathMu.Unlock()
+		return t.resolveFromConfig(hostname)
 	}
 	sshPath = t.sshPath
 	t.sshPathMu.Unlock()
@@ -78,32 +158,86 @@ func (t *Translator) resolve(hostname string) (string, error) {
 	sshCmd := newCommand(sshPath, "-G", hostname)
 	stdout, err := sshCmd.StdoutPipe()
 	if err != nil {
-		return "", err
+		return Resolution{}, err
 	}
 
 	if err := sshCmd.Start(); err != nil {
-		return "", err
+		return Resolution{}, err
 	}
 
-	var resolvedHost string
+	values := map[string][]string{}
 	s := bufio.NewScanner(stdout)
 	for s.Scan() {
 		line := s.Text()
 		parts := strings.SplitN(line, " ", 2)
-		if len(parts) == 2 && parts[0] == "hostname" {
-			resolvedHost = parts[1]
+		if len(parts) == 2 {
+			values[parts[0]] = append(values[parts[0]], parts[1])
 		}
 	}
 
 	err = sshCmd.Wait()
-	if err != nil || resolvedHost == "" {
+	if err != nil {
 		// handle failures by returning the original hostname unchanged
-		resolvedHost = hostname
+		values = nil
+	}
+
+	if t.cacheMap == nil {
+		t.cacheMap = map[string]Resolution{}
+	}
+	res := newResolution(hostname, values)
+	t.cacheMap[strings.ToLower(hostname)] = res
+	return res, nil
+}
+
+// resolveFromConfig resolves hostname by parsing the configuration files.
+func (t *Translator) resolveFromConfig(hostname string) (Resolution, error) {
+	t.configOnce.Do(func() {
+		paths := t.configPaths
+		if len(paths) == 0 {
+			paths = defaultConfigPaths()
+		}
+		t.config, t.configErr = LoadConfig(paths...)
+	})
+	if t.configErr != nil {
+		return Resolution{}, t.configErr
 	}
 
+	res := newResolution(hostname, t.config.evaluate(hostname))
+
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
 	if t.cacheMap == nil {
-		t.cacheMap = map[string]string{}
+		t.cacheMap = map[string]Resolution{}
+	}
+	t.cacheMap[strings.ToLower(hostname)] = res
+	return res, nil
+}
+
+// newResolution returns the resolution of hostname from the values of lowercased
+// keywords, as printed by "ssh -G" or evaluated from the configuration files.
+func newResolution(hostname string, values map[string][]string) Resolution {
+	first := func(keyword string) string {
+		if v := values[keyword]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	res := Resolution{
+		Hostname:      first("hostname"),
+		Port:          defaultPort,
+		User:          first("user"),
+		IdentityFiles: values["identityfile"],
+	}
+	if res.Hostname == "" {
+		res.Hostname = hostname
+	}
+	if port, err := strconv.Atoi(first("port")); err == nil && port > 0 {
+		res.Port = port
+	}
+	for _, keyword := range []string{"proxycommand", "proxyjump"} {
+		if v := first(keyword); v != "" && !strings.EqualFold(v, "none") {
+			res.HasProxyCommand = true
+		}
 	}
-	t.cacheMap[strings.ToLower(hostname)] = resolvedHost
-	return resolvedHost, nil
+	return res
 }
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"reflect"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
@@ -92,6 +93,18 @@ func TestHelperProcess(t *testing.T) {
 		if args[2] == "empty.io" {
 			return nil
 		}
+		if args[2] == "alias.io" {
+			fmt.Fprint(os.Stdout, heredoc.Doc(`
+				hostname ssh.github.com
+				port 443
+				user git
+				identityfile ~/.ssh/work
+				identityfile ~/.ssh/id_ed25519
+				proxycommand none
+				proxyjump bastion.example.com
+			`))
+			return nil
+		}
 		fmt.Fprintf(os.Stdout, "hostname %s\n", args[2])
 		return nil
 	}(os.Args[3:]); err != nil {
@@ -161,3 +174,82 @@ func TestTranslator_caching(t *testing.T) {
 		t.Errorf("expected ssh command to shell out %d times; actual: %d", len(tests), countNewCommand)
 	}
 }
+
+func TestTranslator_Resolve(t *testing.T) {
+	tr := &Translator{
+		lookPath: func(s string) (string, error) {
+			return "/path/to/ssh", nil
+		},
+		newCommand: func(exe string, args ...string) *exec.Cmd {
+			args = append([]string{"-test.run=TestHelperProcess", "--", exe}, args...)
+			c := exec.Command(os.Args[0], args...)
+			c.Env = []string{"GH_WANT_HELPER_PROCESS=1"}
+			return c
+		},
+	}
+
+	tests := []struct {
+		host string
+		want Resolution
+	}{
+		{
+			host: "alias.io",
+			want: Resolution{
+				Hostname:        "ssh.github.com",
+				Port:            443,
+				User:            "git",
+				IdentityFiles:   []string{"~/.ssh/work", "~/.ssh/id_ed25519"},
+				HasProxyCommand: true,
+			},
+		},
+		{
+			host: "empty.io",
+			want: Resolution{Hostname: "empty.io", Port: 22},
+		},
+		{
+			host: "error",
+			want: Resolution{Hostname: "error", Port: 22},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			res, err := tr.Resolve(tt.host)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(res, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, res)
+			}
+		})
+	}
+
+	u, _ := url.Parse("ssh://alias.io/owner/repo.git")
+	if got := tr.Translate(u).String(); got != "ssh://github.com/owner/repo.git" {
+		t.Errorf("expected ssh://github.com/owner/repo.git, got %q", got)
+	}
+}
+
+func TestResolution_WebHost(t *testing.T) {
+	tests := []struct {
+		hostname string
+		port     int
+		want     string
+	}{
+		{hostname: "github.com", port: 22, want: "github.com"},
+		{hostname: "ssh.github.com", port: 443, want: "github.com"},
+		{hostname: "ssh.github.com", port: 22, want: "github.com"},
+		{hostname: "SSH.GitHub.com", port: 443, want: "github.com"},
+		{hostname: "ssh.tenant.ghe.com", port: 22, want: "tenant.ghe.com"},
+		{hostname: "ssh.ghe.example.com", port: 443, want: "ghe.example.com"},
+		{hostname: "ssh.ghe.example.com", port: 22, want: "ssh.ghe.example.com"},
+		{hostname: "ghe.example.com", port: 443, want: "ghe.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s:%d", tt.hostname, tt.port), func(t *testing.T) {
+			res := Resolution{Hostname: tt.hostname, Port: tt.port}
+			if got := res.WebHost(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}